@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/huh"
@@ -77,8 +78,7 @@ var (
 						&cli.BoolFlag{
 							Name:    "install",
 							Aliases: []string{"i"},
-							Usage:   "Run installation tasks after creating the app",
-							Hidden:  true,
+							Usage:   "Run installation tasks after creating the app. Otherwise only post-create tasks and cleanup run, install later with \"lk app install\"",
 						},
 					},
 				},
@@ -89,7 +89,6 @@ var (
 					Action: listTemplates,
 				},
 				{
-					Hidden:    true,
 					Name:      "install",
 					Usage:     "Execute installation defined in " + bootstrap.TaskFile,
 					ArgsUsage: "[DIR] location of the project directory (default: current directory)",
@@ -191,10 +190,13 @@ func listTemplates(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return err
 	}
-
-	if cmd.Bool("json") {
-		util.PrintJSON(templates)
+	// structured output lists every template, marked with is_hidden
+	if structuredOutput(cmd) {
+		printOutput(templates)
 	} else {
+		if !cmd.Bool("include-hidden") {
+			templates = visibleTemplates(templates)
+		}
 		const maxDescLength = 64
 		table := util.CreateTable().Headers("Template", "Description").BorderRow(true)
 		for _, t := range templates {
@@ -206,11 +208,19 @@ func listTemplates(ctx context.Context, cmd *cli.Command) error {
 				desc+"\n\n"+url+"\n"+tags,
 			)
 		}
-		fmt.Println(table)
+		if err := printTable(table); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
+func visibleTemplates(templates []bootstrap.Template) []bootstrap.Template {
+	return slices.DeleteFunc(slices.Clone(templates), func(t bootstrap.Template) bool {
+		return t.IsHidden
+	})
+}
+
 func setupTemplate(ctx context.Context, cmd *cli.Command) error {
 	verbose := cmd.Bool("verbose")
 	install := cmd.Bool("install")
@@ -253,7 +263,11 @@ func setupTemplate(ctx context.Context, cmd *cli.Command) error {
 			Value(&templateURL).
 			WithTheme(util.Theme)
 		var options []huh.Option[string]
-		for _, t := range templateOptions {
+		selectable := templateOptions
+		if !cmd.Bool("include-hidden") {
+			selectable = visibleTemplates(templateOptions)
+		}
+		for _, t := range selectable {
 			descStyle := util.Theme.Help.ShortDesc
 			optionText := t.Name + " " + descStyle.Render("#"+strings.Join(t.Tags, " #"))
 			options = append(options, huh.NewOption(optionText, t.URL))
@@ -305,17 +319,25 @@ func setupTemplate(ctx context.Context, cmd *cli.Command) error {
 		}
 	}
 
-	fmt.Println("Cloning template...")
+	steps := util.NewSteps(os.Stdout, 4)
+	steps.Next("Cloning template")
 	if err := cloneTemplate(ctx, cmd, templateURL, appName); err != nil {
-		return err
+		return steps.Fail(err)
+	}
+
+	// from here on the app directory exists, so failures should say how to
+	// pick up where we left off
+	fail := func(err error) error {
+		printResumeHint(steps, appName)
+		return steps.Fail(err)
 	}
 
 	tf, err := bootstrap.ParseTaskfile(appName)
 	if err != nil {
-		return err
+		return fail(err)
 	}
 
-	fmt.Println("Instantiating environment...")
+	steps.Next("Instantiating environment")
 	addlEnv := &map[string]string{
 		"LIVEKIT_SANDBOX_ID":             sandboxID,
 		"NEXT_PUBLIC_LIVEKIT_SANDBOX_ID": sandboxID,
@@ -332,23 +354,44 @@ func setupTemplate(ctx context.Context, cmd *cli.Command) error {
 	}
 	env, err := instantiateEnv(ctx, cmd, appName, addlEnv, envExampleFile)
 	if err != nil {
-		return err
+		return fail(err)
 	}
 
 	bootstrap.WriteDotEnv(appName, envOutputFile, env)
 
 	if install {
-		fmt.Println("Installing template...")
+		steps.Next("Installing template")
 		if err := doInstall(ctx, bootstrap.TaskInstall, appName, verbose); err != nil {
-			return err
+			return fail(err)
 		}
 	} else {
+		steps.Next("Running post-create tasks")
 		if err := doPostCreate(ctx, cmd, appName, verbose); err != nil {
-			return err
+			return fail(err)
 		}
 	}
 
-	return cleanupTemplate(ctx, cmd, appName)
+	steps.Next("Cleaning up")
+	if err := cleanupTemplate(ctx, cmd, appName); err != nil {
+		return fail(err)
+	}
+
+	steps.Done()
+	return nil
+}
+
+// printResumeHint explains how to finish or discard a partially created app
+// after the given step failed.
+func printResumeHint(steps *util.Steps, appName string) {
+	n, _ := steps.Current()
+	fmt.Fprintf(os.Stderr, "\nThe partially created app was left in ./%s.\n", appName)
+	switch n {
+	case 1, 2:
+		fmt.Fprintf(os.Stderr, "To resume, run `lk app env -w %s` followed by `lk app install %s`.\n", appName, appName)
+	case 3:
+		fmt.Fprintf(os.Stderr, "To resume, run `lk app install %s`.\n", appName)
+	}
+	fmt.Fprintf(os.Stderr, "To start over, remove it with `rm -rf %s`.\n\n", appName)
 }
 
 func cloneTemplate(_ context.Context, cmd *cli.Command, url, appName string) error {
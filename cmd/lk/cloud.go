@@ -32,6 +32,7 @@ import (
 	"github.com/livekit/livekit-cli/pkg/config"
 	"github.com/livekit/livekit-cli/pkg/util"
 	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/logger"
 )
 
 type ClaimAccessKeyResponse struct {
@@ -51,6 +52,11 @@ const (
 	claimKeyEndpoint    = "/cli/claim"
 	confirmAuthEndpoint = "/cli/confirm-auth"
 	revokeKeyEndpoint   = "/cli/revoke"
+
+	// cloudTokenTTL is the lifetime of tokens issued for cloud API calls, and
+	// cloudTokenRefresh is how close to expiry a cached token is replaced
+	cloudTokenTTL     = time.Hour
+	cloudTokenRefresh = 5 * time.Minute
 )
 
 var (
@@ -104,6 +110,18 @@ var (
 						},
 					},
 				},
+				{
+					Name:   "token",
+					Usage:  "Print the cached cloud API token for the current project and its expiry",
+					Action: printCloudToken,
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:  "refresh",
+							Usage: "Discard the cached token and issue a new one",
+						},
+						jsonFlag,
+					},
+				},
 			},
 		},
 	}
@@ -209,6 +227,11 @@ func (a *AuthClient) Deauthenticate(ctx context.Context, projectName, token stri
 	if resp.StatusCode != 200 {
 		return errors.New("access denied")
 	}
+
+	session := config.LoadSession()
+	session.RemoveToken(projectName)
+	_ = session.Persist()
+
 	return cliConfig.RemoveProject(projectName)
 }
 
@@ -240,11 +263,21 @@ func handleAuth(ctx context.Context, cmd *cli.Command) error {
 }
 
 func requireToken(_ context.Context, cmd *cli.Command) (string, error) {
+	t, err := cloudToken(cmd, false)
+	if err != nil {
+		return "", err
+	}
+	return t.Token, nil
+}
+
+// cloudToken returns a token for the chosen project, reusing the one cached in
+// the session file until it is close to expiry.
+func cloudToken(cmd *cli.Command, refresh bool) (*config.CachedToken, error) {
 	if project == nil {
 		var err error
 		project, err = loadProjectDetails(cmd)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 	}
 
@@ -253,15 +286,55 @@ func requireToken(_ context.Context, cmd *cli.Command) (string, error) {
 	// deleting it
 	hash, err := util.HashString(project.APISecret)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	at := auth.NewAccessToken(project.APIKey, project.APISecret).SetIdentity(hash)
+
+	session := config.LoadSession()
+	if cached, ok := session.Token(project.Name); ok && !refresh &&
+		cached.ValidFor(project.APIKey, hash, cloudTokenRefresh) {
+		return &cached, nil
+	}
+
+	at := auth.NewAccessToken(project.APIKey, project.APISecret).
+		SetIdentity(hash).
+		SetValidFor(cloudTokenTTL)
 	token, err := at.ToJWT()
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	cached := config.CachedToken{
+		APIKey:    project.APIKey,
+		Identity:  hash,
+		Token:     token,
+		ExpiresAt: time.Now().Add(cloudTokenTTL).Truncate(time.Second),
+	}
+	session.SetToken(project.Name, cached)
+	if err := session.Persist(); err != nil {
+		// caching is best-effort; the token is still usable
+		logger.Debugw("could not cache cloud token", "error", err)
+	}
+
+	return &cached, nil
+}
+
+func printCloudToken(ctx context.Context, cmd *cli.Command) error {
+	t, err := cloudToken(cmd, cmd.Bool("refresh"))
+	if err != nil {
+		return err
+	}
+
+	if structuredOutput(cmd) {
+		printOutput(map[string]any{
+			"token":      t.Token,
+			"expires_at": t.ExpiresAt,
+		})
+		return nil
 	}
 
-	return token, nil
+	fmt.Println("Token:  ", t.Token)
+	fmt.Printf("Expires: %s (in %s)\n", t.ExpiresAt.Local().Format(time.RFC3339), time.Until(t.ExpiresAt).Round(time.Second))
+	return nil
 }
 
 func tryAuthIfNeeded(ctx context.Context, cmd *cli.Command) error {
@@ -0,0 +1,217 @@
+// Copyright 2024 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/livekit/livekit-cli/pkg/config"
+)
+
+var (
+	ConfigCommands = []*cli.Command{
+		{
+			Name:   "config",
+			Usage:  "View and change CLI settings",
+			Before: loadProjectConfig,
+			Commands: []*cli.Command{
+				{
+					Name:  "telemetry",
+					Usage: "Show or change whether anonymous usage telemetry may be sent",
+					Description: "With no argument, prints the current setting. " +
+						"Setting the DO_NOT_TRACK environment variable always disables telemetry, regardless of this setting.",
+					UsageText: "lk config telemetry [on|off]",
+					ArgsUsage: "[on|off]",
+					Action:    setTelemetry,
+				},
+				{
+					Name:      "set",
+					Usage:     "Change a setting, e.g. the room used when --room is omitted",
+					UsageText: "lk config set KEY VALUE",
+					ArgsUsage: "KEY VALUE",
+					Description: "Settings:\n" +
+						"   defaults.room      room used by commands when --room is omitted\n" +
+						"   defaults.identity  identity used by commands when --identity is omitted",
+					Action: setConfigValue,
+				},
+				{
+					Name:      "unset",
+					Usage:     "Clear a setting",
+					UsageText: "lk config unset KEY",
+					ArgsUsage: "KEY",
+					Action:    unsetConfigValue,
+				},
+				{
+					Name:      "get",
+					Usage:     "Print settings",
+					UsageText: "lk config get [KEY]",
+					ArgsUsage: "[KEY]",
+					Action:    getConfigValue,
+				},
+			},
+		},
+	}
+
+	// settings that can be changed with `lk config set`
+	configKeys = map[string]func(c *config.CLIConfig) *string{
+		"defaults.room":     func(c *config.CLIConfig) *string { return &c.Defaults.Room },
+		"defaults.identity": func(c *config.CLIConfig) *string { return &c.Defaults.Identity },
+	}
+
+	// flags filled in from config defaults, rather than given explicitly
+	defaultedFlags = make(map[string]bool)
+)
+
+func setTelemetry(ctx context.Context, cmd *cli.Command) error {
+	switch cmd.Args().First() {
+	case "":
+		printTelemetryStatus()
+		return nil
+	case "on":
+		cliConfig.DisableTelemetry = false
+	case "off":
+		cliConfig.DisableTelemetry = true
+	default:
+		return errors.New("expected \"on\" or \"off\"")
+	}
+	if err := cliConfig.Persist(); err != nil {
+		return err
+	}
+	printTelemetryStatus()
+	return nil
+}
+
+func printTelemetryStatus() {
+	switch {
+	case config.DoNotTrack():
+		fmt.Println("Telemetry: off (DO_NOT_TRACK is set)")
+	case cliConfig.TelemetryEnabled():
+		fmt.Println("Telemetry: on")
+	default:
+		fmt.Println("Telemetry: off")
+	}
+}
+
+func configValue(key string) (*string, error) {
+	field, ok := configKeys[key]
+	if !ok {
+		keys := slices.Sorted(maps.Keys(configKeys))
+		return nil, fmt.Errorf("unknown setting %q, expected one of %s", key, strings.Join(keys, ", "))
+	}
+	return field(cliConfig), nil
+}
+
+func setConfigValue(ctx context.Context, cmd *cli.Command) error {
+	if cmd.Args().Len() != 2 {
+		return errors.New("expected KEY and VALUE")
+	}
+	value, err := configValue(cmd.Args().Get(0))
+	if err != nil {
+		return err
+	}
+	*value = cmd.Args().Get(1)
+	return cliConfig.Persist()
+}
+
+func unsetConfigValue(ctx context.Context, cmd *cli.Command) error {
+	value, err := configValue(cmd.Args().First())
+	if err != nil {
+		return err
+	}
+	*value = ""
+	return cliConfig.Persist()
+}
+
+func getConfigValue(ctx context.Context, cmd *cli.Command) error {
+	if key := cmd.Args().First(); key != "" {
+		value, err := configValue(key)
+		if err != nil {
+			return err
+		}
+		fmt.Println(*value)
+		return nil
+	}
+	for _, key := range slices.Sorted(maps.Keys(configKeys)) {
+		fmt.Printf("%s=%s\n", key, *configKeys[key](cliConfig))
+	}
+	return nil
+}
+
+// withConfigDefaults makes every command that takes a flag marked with
+// defaultable, such as roomFlag and identityFlag, fall back to the configured
+// defaults when the flag is omitted. This has to happen before required flags
+// are checked, so it's done by wrapping each command's Before hook.
+func withConfigDefaults(cmd *cli.Command) {
+	for _, c := range cmd.Commands {
+		withConfigDefaults(c)
+	}
+	var names []string
+	for _, f := range cmd.Flags {
+		if defaultableFlags[f] {
+			names = append(names, f.Names()[0])
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	before := cmd.Before
+	cmd.Before = func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+		if err := applyConfigDefaults(cmd, names); err != nil {
+			return nil, err
+		}
+		if before != nil {
+			return before(ctx, cmd)
+		}
+		return nil, nil
+	}
+}
+
+func applyConfigDefaults(cmd *cli.Command, names []string) error {
+	conf, err := config.LoadOrCreate()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if cmd.IsSet(name) {
+			continue
+		}
+		var value string
+		switch name {
+		case roomFlag.Name:
+			value = conf.Defaults.Room
+		case identityFlag.Name:
+			value = conf.Defaults.Identity
+		}
+		if value == "" {
+			continue
+		}
+		if err = cmd.Set(name, value); err != nil {
+			return err
+		}
+		defaultedFlags[name] = true
+		if cmd.Bool("verbose") {
+			fmt.Fprintf(os.Stderr, "Using default %s [%s] from config\n", name, value)
+		}
+	}
+	return nil
+}
@@ -18,8 +18,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/urfave/cli/v3"
+	"golang.org/x/term"
 
 	"github.com/livekit/livekit-cli/pkg/util"
 	"github.com/livekit/protocol/livekit"
@@ -36,9 +39,23 @@ var (
 				{
 					Name:      "list",
 					Usage:     "List all agent dispatches in a room",
+					UsageText: "lk dispatch list [OPTIONS] ROOM_NAME",
 					Before:    createDispatchClient,
 					Action:    listAgentDispatches,
 					ArgsUsage: "ROOM_NAME",
+					Flags: []cli.Flag{
+						optional(roomFlag),
+						&cli.BoolFlag{
+							Name:  "watch",
+							Usage: "Keep listing dispatches every --interval until interrupted",
+						},
+						&cli.DurationFlag{
+							Name:  "interval",
+							Usage: "How often to refresh with --watch, as a `TIME` such as 3s",
+							Value: 3 * time.Second,
+						},
+						jsonFlag,
+					},
 				},
 				{
 					Name:      "get",
@@ -91,7 +108,7 @@ func createDispatchClient(ctx context.Context, cmd *cli.Command) (context.Contex
 		return nil, err
 	}
 
-	dispatchClient = lksdk.NewAgentDispatchServiceClient(pc.URL, pc.APIKey, pc.APISecret, withDefaultClientOpts(pc)...)
+	dispatchClient = lksdk.NewAgentDispatchServiceClient(pc.APIURL(), pc.APIKey, pc.APISecret, withDefaultClientOpts(pc)...)
 	return nil, nil
 }
 
@@ -108,39 +125,75 @@ func getAgentDispatch(ctx context.Context, cmd *cli.Command) error {
 		return errors.New("dispatch ID is required")
 	}
 
-	return listDispatchAndPrint(cmd, &livekit.ListAgentDispatchRequest{
+	return listDispatchAndPrint(ctx, cmd, &livekit.ListAgentDispatchRequest{
 		Room:       roomName,
 		DispatchId: id,
 	})
 }
 
 func listAgentDispatches(ctx context.Context, cmd *cli.Command) error {
-	if cmd.Args().Len() == 0 {
-		return cli.ShowSubcommandHelp(cmd)
-	}
 	roomName := cmd.Args().First()
 	if roomName == "" {
+		roomName = cmd.String("room")
+	}
+	if roomName == "" {
+		_ = cli.ShowSubcommandHelp(cmd)
 		return errors.New("room name is required")
 	}
 
-	return listDispatchAndPrint(cmd, &livekit.ListAgentDispatchRequest{
+	req := &livekit.ListAgentDispatchRequest{
 		Room: roomName,
-	})
+	}
+	if !cmd.Bool("watch") {
+		return listDispatchAndPrint(ctx, cmd, req)
+	}
+	return watchAgentDispatches(ctx, cmd, req)
 }
 
-func listDispatchAndPrint(cmd *cli.Command, req *livekit.ListAgentDispatchRequest) error {
-	if cmd.Args().Len() == 0 {
-		return cli.ShowSubcommandHelp(cmd)
+// watchAgentDispatches lists dispatches every --interval until interrupted,
+// redrawing the table in place when writing to a terminal.
+func watchAgentDispatches(ctx context.Context, cmd *cli.Command, req *livekit.ListAgentDispatchRequest) error {
+	interval := cmd.Duration("interval")
+	if interval <= 0 {
+		return errors.New("--interval must be positive")
 	}
+	structured := structuredOutput(cmd)
+	redraw := !structured && term.IsTerminal(int(os.Stdout.Fd()))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if redraw {
+			// move to the top left and clear the screen
+			fmt.Print("\033[H\033[2J")
+		}
+		if !structured {
+			fmt.Printf("Dispatches in room %s, every %s (%s)\n", req.Room, interval, time.Now().Format(time.TimeOnly))
+		}
+		if err := listDispatchAndPrint(ctx, cmd, req); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func listDispatchAndPrint(ctx context.Context, cmd *cli.Command, req *livekit.ListAgentDispatchRequest) error {
 	if cmd.Bool("verbose") {
-		util.PrintJSON(req)
+		util.PrintJSONRedacted(req)
 	}
-	res, err := dispatchClient.ListDispatch(context.Background(), req)
+	res, err := dispatchClient.ListDispatch(ctx, req)
 	if err != nil {
 		return err
 	}
-	if cmd.Bool("json") {
-		util.PrintJSON(res)
+	if structuredOutput(cmd) {
+		printOutput(res)
 	} else {
 		table := util.CreateTable().
 			Headers("DispatchID", "Room", "AgentName", "Metadata")
@@ -156,7 +209,9 @@ func listDispatchAndPrint(cmd *cli.Command, req *livekit.ListAgentDispatchReques
 				item.Metadata,
 			)
 		}
-		fmt.Println(table)
+		if err := printTable(table); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -179,7 +234,7 @@ func createAgentDispatch(ctx context.Context, cmd *cli.Command) error {
 		return errors.New("agent-name is required")
 	}
 	if cmd.Bool("verbose") {
-		util.PrintJSON(req)
+		util.PrintJSONRedacted(req)
 	}
 
 	info, err := dispatchClient.CreateDispatch(context.Background(), req)
@@ -187,8 +242,8 @@ func createAgentDispatch(ctx context.Context, cmd *cli.Command) error {
 		return err
 	}
 
-	if cmd.Bool("json") {
-		util.PrintJSON(info)
+	if structuredOutput(cmd) {
+		printOutput(info)
 	} else {
 		fmt.Printf("Dispatch created: %v\n", info)
 	}
@@ -218,8 +273,8 @@ func deleteAgentDispatch(ctx context.Context, cmd *cli.Command) error {
 		return err
 	}
 
-	if cmd.Bool("json") {
-		util.PrintJSON(info)
+	if structuredOutput(cmd) {
+		printOutput(info)
 	} else {
 		fmt.Printf("Dispatch deleted: %v\n", info)
 	}
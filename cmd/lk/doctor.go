@@ -0,0 +1,162 @@
+// Copyright 2024 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"time"
+
+	"github.com/twitchtv/twirp"
+	"github.com/urfave/cli/v3"
+
+	"github.com/livekit/livekit-cli/pkg/config"
+	"github.com/livekit/livekit-cli/pkg/util"
+	"github.com/livekit/protocol/livekit"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+const (
+	checkPass = "pass"
+	checkWarn = "warn"
+	checkFail = "fail"
+
+	// tokens are rejected when the clocks disagree by more than the server's
+	// leeway for token validity
+	maxClockSkew = time.Minute
+	// checks against the server give up after this long
+	doctorTimeout = 10 * time.Second
+)
+
+var (
+	DoctorCommands = []*cli.Command{
+		{
+			Name:   "doctor",
+			Usage:  "Check the CLI configuration, project credentials and connectivity",
+			Action: runDoctor,
+		},
+	}
+)
+
+type doctorCheck struct {
+	name    string
+	status  string
+	details string
+}
+
+func runDoctor(ctx context.Context, cmd *cli.Command) error {
+	var checks []doctorCheck
+	add := func(name, status, details string) {
+		checks = append(checks, doctorCheck{name, status, details})
+	}
+
+	if _, err := config.LoadOrCreate(); err != nil {
+		add("Config file", checkFail, err.Error())
+	} else {
+		add("Config file", checkPass, "readable")
+	}
+
+	pc, err := loadProjectDetails(cmd)
+	if err != nil {
+		add("Project", checkFail, err.Error())
+	} else {
+		name := pc.Name
+		if name == "" {
+			name = "(from flags or environment)"
+		}
+		add("Project", checkPass, fmt.Sprintf("%s, %s", name, pc.URL))
+		checks = append(checks, checkServer(ctx, pc)...)
+	}
+
+	for _, tool := range []string{"git", "docker"} {
+		if p, err := exec.LookPath(tool); err != nil {
+			add("Tool: "+tool, checkWarn, "not found in PATH")
+		} else {
+			add("Tool: "+tool, checkPass, p)
+		}
+	}
+
+	failed := 0
+	table := util.CreateTable().Headers("Check", "Status", "Details")
+	for _, c := range checks {
+		if c.status == checkFail {
+			failed++
+		}
+		table.Row(c.name, c.status, c.details)
+	}
+	if err := printTable(table); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+// checkServer verifies the project URL is reachable, the credentials are accepted,
+// and the local clock agrees with the server closely enough for tokens to be valid.
+func checkServer(ctx context.Context, pc *config.ProjectConfig) []doctorCheck {
+	ctx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+
+	var checks []doctorCheck
+
+	httpURL := lksdk.ToHttpURL(pc.APIURL())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, httpURL, nil)
+	if err != nil {
+		return append(checks, doctorCheck{"Connectivity", checkFail, err.Error()})
+	}
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return append(checks, doctorCheck{"Connectivity", checkFail, err.Error()})
+	}
+	_ = resp.Body.Close()
+	checks = append(checks, doctorCheck{"Connectivity", checkPass, fmt.Sprintf("%s reachable in %s", httpURL, time.Since(start).Round(time.Millisecond))})
+
+	if date, err := http.ParseTime(resp.Header.Get("Date")); err != nil {
+		checks = append(checks, doctorCheck{"Clock skew", checkWarn, "server did not report its time"})
+	} else {
+		checks = append(checks, clockSkewCheck(time.Since(date).Round(time.Second)))
+	}
+
+	client := lksdk.NewRoomServiceClient(pc.APIURL(), pc.APIKey, pc.APISecret, withDefaultClientOpts(pc)...)
+	_, err = client.ListRooms(ctx, &livekit.ListRoomsRequest{Names: []string{"lk-doctor"}})
+	return append(checks, credentialsCheck(err))
+}
+
+func clockSkewCheck(skew time.Duration) doctorCheck {
+	if skew.Abs() > maxClockSkew {
+		return doctorCheck{"Clock skew", checkFail, fmt.Sprintf("local clock is off by %s, tokens may be rejected", skew)}
+	}
+	return doctorCheck{"Clock skew", checkPass, skew.String()}
+}
+
+// credentialsCheck classifies the result of an authenticated API call.
+func credentialsCheck(err error) doctorCheck {
+	var terr twirp.Error
+	switch {
+	case err == nil:
+		return doctorCheck{"Credentials", checkPass, "API key accepted"}
+	case errors.As(err, &terr) && (terr.Code() == twirp.Unauthenticated || terr.Code() == twirp.PermissionDenied):
+		return doctorCheck{"Credentials", checkFail, "API key or secret rejected: " + terr.Msg()}
+	default:
+		return doctorCheck{"Credentials", checkFail, err.Error()}
+	}
+}
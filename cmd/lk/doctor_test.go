@@ -0,0 +1,58 @@
+// Copyright 2024 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/twitchtv/twirp"
+)
+
+func TestClockSkewCheck(t *testing.T) {
+	for _, tc := range []struct {
+		skew     time.Duration
+		expected string
+	}{
+		{0, checkPass},
+		{45 * time.Second, checkPass},
+		{-45 * time.Second, checkPass},
+		{time.Minute, checkPass},
+		{61 * time.Second, checkFail},
+		{-2 * time.Minute, checkFail},
+	} {
+		assert.Equal(t, tc.expected, clockSkewCheck(tc.skew).status, tc.skew.String())
+	}
+}
+
+func TestCredentialsCheck(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		err      error
+		expected string
+		details  string
+	}{
+		{"accepted", nil, checkPass, "API key accepted"},
+		{"unauthenticated", twirp.NewError(twirp.Unauthenticated, "invalid token"), checkFail, "API key or secret rejected: invalid token"},
+		{"permission denied", twirp.NewError(twirp.PermissionDenied, "no permission"), checkFail, "API key or secret rejected: no permission"},
+		{"other", errors.New("connection refused"), checkFail, "connection refused"},
+	} {
+		c := credentialsCheck(tc.err)
+		assert.Equal(t, tc.expected, c.status, tc.name)
+		assert.Equal(t, tc.details, c.details, tc.name)
+	}
+}
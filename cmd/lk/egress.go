@@ -21,12 +21,17 @@ import (
 	"net/url"
 	"os"
 	"os/signal"
+	"path"
 	"reflect"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/pkg/browser"
+	"github.com/twitchtv/twirp"
 	"github.com/urfave/cli/v3"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
@@ -41,6 +46,9 @@ import (
 
 type egressType string
 
+// template used by room composite egress when custom_base_url is not set
+const defaultEgressTemplateURL = "https://recorder.livekit.io/#"
+
 const (
 	EgressTypeRoomComposite  egressType = "room-composite"
 	EgressTypeParticipant    egressType = "participant"
@@ -57,7 +65,7 @@ var (
 	- "track-composite" captures an audio and a video track
 	- "web" captures any website, with a lifecycle detached from LiveKit rooms
 
-REQUEST_JSON is one of:
+REQUEST_JSON is a file, literal JSON, or - to read it from stdin. It is one of:
 	- ` + reflect.TypeFor[livekit.RoomCompositeEgressRequest]().Name() + `
 	- ` + reflect.TypeFor[livekit.ParticipantEgressRequest]().Name() + `
 	- ` + reflect.TypeFor[livekit.TrackEgressRequest]().Name() + `
@@ -82,8 +90,20 @@ var (
 					Flags: []cli.Flag{
 						&cli.StringFlag{
 							Name:  "type",
-							Usage: "Specify `TYPE` of egress (see above)",
-							Value: string(EgressTypeRoomComposite),
+							Usage: "Specify `TYPE` of egress (see above), inferred from REQUEST_JSON when omitted",
+						},
+						&cli.StringFlag{
+							Name:  "room",
+							Usage: "`NAME` of the room, overrides room_name of a participant egress request",
+						},
+						&cli.StringFlag{
+							Name:  "identity",
+							Usage: "`ID` of the participant to record, implies --type participant",
+						},
+						&cli.StringFlag{
+							Name:      "preview",
+							Usage:     "Instead of starting a room-composite or web egress, render its page in a headless browser and save a PNG screenshot to `FILE`",
+							TakesFile: true,
 						},
 					},
 					ArgsUsage: "REQUEST_JSON",
@@ -91,8 +111,7 @@ var (
 				{
 					Name:   "list",
 					Usage:  "List and search active egresses",
-					Before: createEgressClient,
-					Action: listEgress,
+					Action: forEachProject(createEgressClient, listEgress),
 					Flags: []cli.Flag{
 						&cli.StringSliceFlag{
 							Name:  "id",
@@ -107,19 +126,59 @@ var (
 							Aliases: []string{"a"},
 							Usage:   "Lists only active egresses",
 						},
+						&cli.BoolFlag{
+							Name:  "summary",
+							Usage: "Print counts of active egresses per room and type instead of listing them. Implies --active",
+						},
 						jsonFlag,
+						&cli.BoolFlag{
+							Name:  "json-lines",
+							Usage: "Output one compact JSON object per egress as they are listed",
+						},
+						templateFlag[livekit.EgressInfo](),
 					},
 				},
 				{
-					Name:   "stop",
-					Usage:  "Stop an active egress",
+					Name:      "stop",
+					Usage:     "Stop an active egress",
+					ArgsUsage: "[ID...]",
+					Description: "Egress IDs may be given with --id or as arguments. Pass - to read newline-delimited IDs from stdin, e.g.\n" +
+						"   lk egress list --active --template '{{.EgressId}}' | lk egress stop -\n\n" +
+						"To stop every active egress in a room, use --room NAME --all.",
 					Before: createEgressClient,
 					Action: stopEgress,
 					Flags: []cli.Flag{
 						&cli.StringSliceFlag{
-							Name:     "id",
-							Usage:    "Egress ID to stop, can be specified multiple times",
-							Required: true,
+							Name:  "id",
+							Usage: "Egress `ID` to stop, can be specified multiple times",
+						},
+						&cli.StringFlag{
+							Name:  "room",
+							Usage: "With --all, stop the active egresses of room `NAME`",
+						},
+						&cli.BoolFlag{
+							Name:  "all",
+							Usage: "Stop all active egresses in --room",
+						},
+					},
+				},
+				{
+					Name:      "wait",
+					Usage:     "Wait for an egress to complete",
+					ArgsUsage: "ID",
+					Description: "Polls the egress until it completes, printing each status change. Exits with an error\n" +
+						"if the egress fails or is aborted, or if --timeout elapses first.",
+					Before: createEgressClient,
+					Action: waitEgress,
+					Flags: []cli.Flag{
+						&cli.DurationFlag{
+							Name:  "interval",
+							Usage: "`TIME` between status checks",
+							Value: 2 * time.Second,
+						},
+						&cli.DurationFlag{
+							Name:  "timeout",
+							Usage: "Give up after `TIME` (default: wait indefinitely)",
 						},
 					},
 				},
@@ -142,10 +201,25 @@ var (
 							Usage:    "`NUMBER` of publishers",
 							Required: true,
 						},
-						&cli.StringFlag{
+						defaultable(&cli.StringFlag{
 							Name:     "room",
 							Usage:    "`NAME` of the room",
 							Required: false,
+						}),
+						&cli.DurationFlag{
+							Name:  "speaker-interval",
+							Usage: "`TIME` to pause between simulated speaker changes, in whole seconds",
+							Value: time.Second,
+						},
+						&cli.IntFlag{
+							Name:  "active-speakers",
+							Usage: "`NUMBER` of publishers speaking at the same time",
+							Value: 1,
+						},
+						&cli.StringFlag{
+							Name:  "publishers-video-quality",
+							Usage: "`QUALITY` of video to publish (\"high\", \"medium\", or \"low\")",
+							Value: "high",
 						},
 					},
 				},
@@ -163,9 +237,13 @@ var (
 						},
 						&cli.StringFlag{
 							Name:     "layout",
-							Usage:    "new web layout",
+							Usage:    "New web `LAYOUT`, one of " + strings.Join(knownLayouts, ", "),
 							Required: true,
 						},
+						&cli.BoolFlag{
+							Name:  "custom",
+							Usage: "Skip layout validation, for layouts defined by a custom template",
+						},
 					},
 				},
 				{
@@ -271,7 +349,7 @@ var (
 			Name:   "list-egress",
 			Usage:  "List all active egress",
 			Before: createEgressClient,
-			Action: listEgress,
+			Action: printListing(listEgress),
 			Flags: []cli.Flag{
 				&cli.StringSliceFlag{
 					Name:  "id",
@@ -304,6 +382,10 @@ var (
 					Usage:    "new web layout",
 					Required: true,
 				},
+				&cli.BoolFlag{
+					Name:  "custom",
+					Usage: "skip layout validation, for layouts defined by a custom template",
+				},
 			},
 		},
 		{
@@ -364,11 +446,11 @@ var (
 					Usage:    "`NUMBER` of publishers",
 					Required: true,
 				},
-				&cli.StringFlag{
+				defaultable(&cli.StringFlag{
 					Name:     "room",
 					Usage:    "`NAME` of the room",
 					Required: false,
-				},
+				}),
 			},
 			SkipFlagParsing:        false,
 			HideHelp:               false,
@@ -387,12 +469,39 @@ func createEgressClient(ctx context.Context, cmd *cli.Command) (context.Context,
 		return nil, err
 	}
 
-	egressClient = lksdk.NewEgressClient(pc.URL, pc.APIKey, pc.APISecret, withDefaultClientOpts(pc)...)
+	egressClient = lksdk.NewEgressClient(pc.APIURL(), pc.APIKey, pc.APISecret, withDefaultClientOpts(pc)...)
 	return nil, nil
 }
 
 func handleEgressStart(ctx context.Context, cmd *cli.Command) error {
-	switch cmd.String("type") {
+	reqFile, err := extractArg(cmd)
+	if err != nil {
+		return err
+	}
+	reqBytes, err := readRequestBytes(reqFile)
+	if err != nil {
+		return err
+	}
+
+	typ := egressType(cmd.String("type"))
+	if typ == "" && cmd.IsSet("identity") {
+		typ = EgressTypeParticipant
+	}
+	detected, detectErr := inferEgressType(reqBytes)
+	if typ == "" {
+		if detectErr != nil {
+			return detectErr
+		}
+		typ = detected
+	} else if detectErr == nil && detected != typ && !cmd.IsSet("identity") {
+		return fmt.Errorf("request looks like a %q egress, but --type is %q", detected, typ)
+	}
+
+	if file := cmd.String("preview"); file != "" {
+		return previewEgress(ctx, cmd, typ, reqBytes, file)
+	}
+
+	switch string(typ) {
 	case string(EgressTypeRoomComposite):
 		return startRoomCompositeEgress(ctx, cmd)
 	case string(EgressTypeWeb):
@@ -404,7 +513,59 @@ func handleEgressStart(ctx context.Context, cmd *cli.Command) error {
 	case string(EgressTypeTrackComposite):
 		return startTrackCompositeEgress(ctx, cmd)
 	default:
-		return errors.New("unrecognized egress type " + util.WrapWith("\"")(cmd.String("type")))
+		return errors.New("unrecognized egress type " + util.WrapWith("\"")(string(typ)))
+	}
+}
+
+// inferEgressType determines which egress request REQUEST_JSON holds, by strictly
+// unmarshalling it into each request type and checking the fields that type requires.
+func inferEgressType(data []byte) (egressType, error) {
+	candidates := []struct {
+		typ     egressType
+		matches func([]byte) bool
+	}{
+		{EgressTypeRoomComposite, matchesEgressRequest(func(r *livekit.RoomCompositeEgressRequest) bool {
+			return r.RoomName != ""
+		})},
+		{EgressTypeParticipant, matchesEgressRequest(func(r *livekit.ParticipantEgressRequest) bool {
+			return r.RoomName != "" && r.Identity != ""
+		})},
+		{EgressTypeTrack, matchesEgressRequest(func(r *livekit.TrackEgressRequest) bool {
+			return r.RoomName != "" && r.TrackId != ""
+		})},
+		{EgressTypeTrackComposite, matchesEgressRequest(func(r *livekit.TrackCompositeEgressRequest) bool {
+			return r.RoomName != "" && (r.AudioTrackId != "" || r.VideoTrackId != "")
+		})},
+		{EgressTypeWeb, matchesEgressRequest(func(r *livekit.WebEgressRequest) bool {
+			return r.Url != ""
+		})},
+	}
+
+	var matched []string
+	var typ egressType
+	for _, c := range candidates {
+		if c.matches(data) {
+			matched = append(matched, string(c.typ))
+			typ = c.typ
+		}
+	}
+	switch len(matched) {
+	case 0:
+		return "", errors.New("could not determine egress type from request, use --type")
+	case 1:
+		return typ, nil
+	default:
+		return "", fmt.Errorf("request matches multiple egress types (%s), use --type", strings.Join(matched, ", "))
+	}
+}
+
+func matchesEgressRequest[T any, P protoType[T]](hasRequired func(P) bool) func([]byte) bool {
+	return func(data []byte) bool {
+		var req P = new(T)
+		if err := unmarshaller.Unmarshal(data, req); err != nil {
+			return false
+		}
+		return hasRequired(req)
 	}
 }
 
@@ -421,6 +582,10 @@ func startRoomCompositeEgress(ctx context.Context, cmd *cli.Command) error {
 	}
 
 	printInfo(info)
+	printOutputLocations(
+		appendNonNil(req.FileOutputs, req.GetFile()),
+		appendNonNil(req.SegmentOutputs, req.GetSegments()),
+	)
 	return nil
 }
 
@@ -451,6 +616,10 @@ func startWebEgress(ctx context.Context, cmd *cli.Command) error {
 	}
 
 	printInfo(info)
+	printOutputLocations(
+		appendNonNil(req.FileOutputs, req.GetFile()),
+		appendNonNil(req.SegmentOutputs, req.GetSegments()),
+	)
 	return nil
 }
 
@@ -474,6 +643,15 @@ func startParticipantEgress(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return err
 	}
+	if room := cmd.String("room"); room != "" {
+		req.RoomName = room
+	}
+	if identity := cmd.String("identity"); identity != "" {
+		req.Identity = identity
+	}
+	if err = validateEgressParticipant(ctx, cmd, req.RoomName, req.Identity); err != nil {
+		return err
+	}
 
 	info, err := egressClient.StartParticipantEgress(ctx, req)
 	if err != nil {
@@ -484,6 +662,32 @@ func startParticipantEgress(ctx context.Context, cmd *cli.Command) error {
 	return nil
 }
 
+// Make sure the participant is present and publishing, otherwise the egress
+// would start only to fail immediately.
+func validateEgressParticipant(ctx context.Context, cmd *cli.Command, roomName, identity string) error {
+	if roomName == "" || identity == "" {
+		return errors.New("participant egress requires a room name and identity")
+	}
+	if _, err := createRoomClient(ctx, cmd); err != nil {
+		return err
+	}
+	p, err := roomClient.GetParticipant(ctx, &livekit.RoomParticipantIdentity{
+		Room:     roomName,
+		Identity: identity,
+	})
+	if err != nil {
+		var terr twirp.Error
+		if errors.As(err, &terr) && terr.Code() == twirp.NotFound {
+			return fmt.Errorf("participant %s not found in room %s", identity, roomName)
+		}
+		return err
+	}
+	if len(p.Tracks) == 0 {
+		return fmt.Errorf("participant %s has no published tracks", identity)
+	}
+	return nil
+}
+
 func _deprecatedStartParticipantEgress(ctx context.Context, cmd *cli.Command) error {
 	req := &livekit.ParticipantEgressRequest{}
 	if err := unmarshalEgressRequest(cmd, req); err != nil {
@@ -569,92 +773,220 @@ func unmarshalEgressRequest(cmd *cli.Command, req proto.Message) error {
 	}
 
 	if cmd.Bool("verbose") {
-		util.PrintJSON(req)
+		util.PrintJSONRedacted(req)
 	}
 	return nil
 }
 
-func listEgress(ctx context.Context, cmd *cli.Command) error {
+func listEgress(ctx context.Context, cmd *cli.Command) (*listing, error) {
+	jsonLines := cmd.Bool("json-lines")
+	if jsonLines && (structuredOutput(cmd) || cmd.IsSet("template") || cmd.Bool("summary")) {
+		return nil, errors.New("--json-lines cannot be combined with --json, --template or --summary")
+	}
+
+	// with --json-lines, items are written out as they arrive rather than collected
 	var items []*livekit.EgressInfo
+	collect := func(res *livekit.ListEgressResponse) error {
+		if !jsonLines {
+			items = append(items, res.Items...)
+			return nil
+		}
+		for _, item := range res.Items {
+			if err := util.PrintJSONLine(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	if cmd.IsSet("id") {
 		for _, id := range cmd.StringSlice("id") {
 			res, err := egressClient.ListEgress(ctx, &livekit.ListEgressRequest{
 				EgressId: id,
 			})
 			if err != nil {
-				return err
+				return nil, err
+			}
+			if err = collect(res); err != nil {
+				return nil, err
 			}
-			items = append(items, res.Items...)
 		}
 	} else {
 		res, err := egressClient.ListEgress(ctx, &livekit.ListEgressRequest{
 			RoomName: cmd.String("room"),
-			Active:   cmd.Bool("active"),
+			Active:   cmd.Bool("active") || cmd.Bool("summary"),
 		})
 		if err != nil {
-			return err
+			return nil, err
+		}
+		if err = collect(res); err != nil {
+			return nil, err
 		}
-		items = res.Items
 	}
 
-	if cmd.Bool("json") {
-		util.PrintJSON(items)
-	} else {
-		table := util.CreateTable().
-			Headers("EgressID", "Status", "Type", "Source", "Started At", "Error")
-		for _, item := range items {
-			var startedAt string
-			if item.StartedAt != 0 {
-				startedAt = fmt.Sprint(time.Unix(0, item.StartedAt))
-			}
-			var egressType, egressSource string
-			switch req := item.Request.(type) {
-			case *livekit.EgressInfo_RoomComposite:
-				egressType = "room_composite"
-				egressSource = req.RoomComposite.RoomName
-			case *livekit.EgressInfo_Web:
-				egressType = "web"
-				egressSource = req.Web.Url
-			case *livekit.EgressInfo_Participant:
-				egressType = "participant"
-				egressSource = fmt.Sprintf("%s/%s", req.Participant.RoomName, req.Participant.Identity)
-			case *livekit.EgressInfo_TrackComposite:
-				egressType = "track_composite"
-				trackIDs := make([]string, 0)
-				if req.TrackComposite.VideoTrackId != "" {
-					trackIDs = append(trackIDs, req.TrackComposite.VideoTrackId)
-				}
-				if req.TrackComposite.AudioTrackId != "" {
-					trackIDs = append(trackIDs, req.TrackComposite.AudioTrackId)
+	if jsonLines {
+		return &listing{}, nil
+	}
+
+	if cmd.Bool("summary") {
+		summary := summarizeEgress(items)
+		return &listing{data: summary, print: func() error {
+			return printEgressSummary(cmd, summary)
+		}}, nil
+	}
+
+	return &listing{data: items, print: func() error {
+
+		if cmd.IsSet("template") {
+			return util.PrintTemplate(cmd.String("template"), items...)
+		} else if structuredOutput(cmd) {
+			printOutput(items)
+		} else {
+			table := util.CreateTable().
+				Headers("EgressID", "Status", "Type", "Source", "Started At", "Error")
+			for _, item := range items {
+				var startedAt string
+				if item.StartedAt != 0 {
+					startedAt = fmt.Sprint(time.Unix(0, item.StartedAt))
 				}
-				egressSource = fmt.Sprintf("%s/%s", req.TrackComposite.RoomName, strings.Join(trackIDs, ","))
-			case *livekit.EgressInfo_Track:
-				egressType = "track"
-				egressSource = fmt.Sprintf("%s/%s", req.Track.RoomName, req.Track.TrackId)
+				egressType, egressSource := describeEgress(item)
+				table.Row(
+					item.EgressId,
+					item.Status.String(),
+					egressType,
+					egressSource,
+					startedAt,
+					item.Error,
+				)
+			}
+			if err := printTable(table); err != nil {
+				return err
 			}
-			table.Row(
-				item.EgressId,
-				item.Status.String(),
-				egressType,
-				egressSource,
-				startedAt,
-				item.Error,
-			)
 		}
-		fmt.Println(table)
+		return nil
+	}}, nil
+}
+
+func describeEgress(item *livekit.EgressInfo) (egressType, egressSource string) {
+	switch req := item.Request.(type) {
+	case *livekit.EgressInfo_RoomComposite:
+		egressType = "room_composite"
+		egressSource = req.RoomComposite.RoomName
+	case *livekit.EgressInfo_Web:
+		egressType = "web"
+		egressSource = req.Web.Url
+	case *livekit.EgressInfo_Participant:
+		egressType = "participant"
+		egressSource = fmt.Sprintf("%s/%s", req.Participant.RoomName, req.Participant.Identity)
+	case *livekit.EgressInfo_TrackComposite:
+		egressType = "track_composite"
+		trackIDs := make([]string, 0)
+		if req.TrackComposite.VideoTrackId != "" {
+			trackIDs = append(trackIDs, req.TrackComposite.VideoTrackId)
+		}
+		if req.TrackComposite.AudioTrackId != "" {
+			trackIDs = append(trackIDs, req.TrackComposite.AudioTrackId)
+		}
+		egressSource = fmt.Sprintf("%s/%s", req.TrackComposite.RoomName, strings.Join(trackIDs, ","))
+	case *livekit.EgressInfo_Track:
+		egressType = "track"
+		egressSource = fmt.Sprintf("%s/%s", req.Track.RoomName, req.Track.TrackId)
 	}
+	return
+}
+
+type egressCount struct {
+	Room  string `json:"room"`
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+}
 
+type egressSummary struct {
+	Total  int            `json:"total"`
+	Groups []*egressCount `json:"groups"`
+}
+
+// summarizeEgress groups egresses by room and type. The egress API does not
+// report which node an egress runs on, so room is the finest grouping offered.
+func summarizeEgress(items []*livekit.EgressInfo) *egressSummary {
+	counts := make([]*egressCount, 0)
+	index := make(map[[2]string]*egressCount)
+	for _, item := range items {
+		egressType, _ := describeEgress(item)
+		key := [2]string{item.RoomName, egressType}
+		c, ok := index[key]
+		if !ok {
+			c = &egressCount{Room: item.RoomName, Type: egressType}
+			index[key] = c
+			counts = append(counts, c)
+		}
+		c.Count++
+	}
+	slices.SortFunc(counts, func(a, b *egressCount) int {
+		if n := b.Count - a.Count; n != 0 {
+			return n
+		}
+		return strings.Compare(a.Room+a.Type, b.Room+b.Type)
+	})
+	return &egressSummary{Total: len(items), Groups: counts}
+}
+
+func printEgressSummary(cmd *cli.Command, summary *egressSummary) error {
+	if structuredOutput(cmd) {
+		printOutput(summary)
+		return nil
+	}
+
+	table := util.CreateTable().Headers("Room", "Type", "Active")
+	for _, c := range summary.Groups {
+		table.Row(c.Room, c.Type, strconv.Itoa(c.Count))
+	}
+	if err := printTable(table); err != nil {
+		return err
+	}
+	fmt.Printf("%d active egress(es) in %d room(s)\n", summary.Total, countRooms(summary.Groups))
 	return nil
 }
 
+func countRooms(counts []*egressCount) int {
+	rooms := make(map[string]struct{})
+	for _, c := range counts {
+		rooms[c.Room] = struct{}{}
+	}
+	return len(rooms)
+}
+
+// knownLayouts are the layouts supported by the default egress template
+var knownLayouts = []string{
+	"grid", "grid-light",
+	"speaker", "speaker-light",
+	"single-speaker", "single-speaker-light",
+}
+
+func validateLayout(layout string) error {
+	if slices.Contains(knownLayouts, layout) {
+		return nil
+	}
+	if match := util.ClosestMatch(layout, knownLayouts, 3); match != "" {
+		return fmt.Errorf("unknown layout %q, did you mean %q? Use --custom for template-defined layouts", layout, match)
+	}
+	return fmt.Errorf("unknown layout %q, expected one of %s. Use --custom for template-defined layouts", layout, strings.Join(knownLayouts, ", "))
+}
+
 func updateLayout(ctx context.Context, cmd *cli.Command) error {
 	egressId := cmd.String("id")
 	if egressId == "" {
 		egressId = cmd.Args().First()
 	}
+	layout := cmd.String("layout")
+	if !cmd.Bool("custom") {
+		if err := validateLayout(layout); err != nil {
+			return err
+		}
+	}
 	info, err := egressClient.UpdateLayout(ctx, &livekit.UpdateLayoutRequest{
 		EgressId: egressId,
-		Layout:   cmd.String("layout"),
+		Layout:   layout,
 	})
 	if err != nil {
 		return err
@@ -669,6 +1001,17 @@ func updateStream(ctx context.Context, cmd *cli.Command) error {
 	if egressId == "" {
 		egressId = cmd.Args().First()
 	}
+	// urls to remove are passed through as-is, since they may name outputs
+	// which have already gone away
+	var errs []error
+	for _, u := range cmd.StringSlice("add-urls") {
+		if err := validateStreamURL(u); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
 	info, err := egressClient.UpdateStream(ctx, &livekit.UpdateStreamRequest{
 		EgressId:         egressId,
 		AddOutputUrls:    cmd.StringSlice("add-urls"),
@@ -682,31 +1025,187 @@ func updateStream(ctx context.Context, cmd *cli.Command) error {
 	return nil
 }
 
+var streamURLSchemes = []string{"rtmp", "rtmps", "srt", "srts"}
+
+// validateStreamURL checks that raw is a well-formed RTMP or SRT url, so that
+// typos are caught before the request is sent.
+func validateStreamURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid url %q: %w", raw, err)
+	}
+	if !slices.Contains(streamURLSchemes, strings.ToLower(u.Scheme)) {
+		return fmt.Errorf("invalid url %q: scheme must be one of %s", raw, strings.Join(streamURLSchemes, ", "))
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid url %q: missing host", raw)
+	}
+	return nil
+}
+
+// maxConcurrentStops bounds how many egresses are stopped at once
+const maxConcurrentStops = 8
+
 func stopEgress(ctx context.Context, cmd *cli.Command) error {
-	ids := cmd.StringSlice("id")
-	var errors []error
-	for _, id := range ids {
-		_, err := egressClient.StopEgress(ctx, &livekit.StopEgressRequest{
-			EgressId: id,
-		})
-		if err != nil {
-			errors = append(errors, err)
-			fmt.Println("Error stopping Egress", id, err)
+	ids, err := collectIDs(cmd, "id")
+	if err != nil {
+		return err
+	}
+	roomName := cmd.String("room")
+	if roomName != "" && !cmd.Bool("all") {
+		return errors.New("--room requires --all")
+	}
+	if cmd.Bool("all") {
+		// never stop egresses across the whole project by accident
+		if roomName == "" && len(ids) == 0 {
+			return errors.New("--all requires --room or egress IDs")
+		}
+		if roomName != "" {
+			res, err := egressClient.ListEgress(ctx, &livekit.ListEgressRequest{
+				RoomName: roomName,
+				Active:   true,
+			})
+			if err != nil {
+				return err
+			}
+			if len(res.Items) == 0 && len(ids) == 0 {
+				fmt.Println("No active egresses in room", roomName)
+				return nil
+			}
+			for _, item := range res.Items {
+				if !slices.Contains(ids, item.EgressId) {
+					ids = append(ids, item.EgressId)
+				}
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return errors.New("at least one egress ID is required")
+	}
+
+	errs := make([]error, len(ids))
+	sem := make(chan struct{}, maxConcurrentStops)
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			_, errs[i] = egressClient.StopEgress(ctx, &livekit.StopEgressRequest{
+				EgressId: id,
+			})
+		}()
+	}
+	wg.Wait()
+
+	// report in the order given, regardless of which finished first
+	stopped := 0
+	for i, id := range ids {
+		if errs[i] != nil {
+			fmt.Println("Error stopping Egress", id, errs[i])
+			errs[i] = fmt.Errorf("%s: %w", id, errs[i])
 		} else {
 			fmt.Println("Stopping Egress", id)
+			stopped++
 		}
 	}
-	if len(errors) != 0 {
-		return errors[0]
+	if len(ids) > 1 {
+		fmt.Printf("Stopped %d of %d egresses, %d failed\n", stopped, len(ids), len(ids)-stopped)
+	}
+	return errors.Join(errs...)
+}
+
+func waitEgress(ctx context.Context, cmd *cli.Command) error {
+	egressID, err := extractArg(cmd)
+	if err != nil {
+		return err
+	}
+	interval := cmd.Duration("interval")
+	if interval <= 0 {
+		return errors.New("--interval must be positive")
+	}
+	// scripts rely on the exit status to tell whether the egress succeeded
+	if err = awaitEgress(ctx, egressID, interval, cmd.Duration("timeout")); err != nil {
+		return exitWithError(err)
 	}
 	return nil
 }
 
+// awaitEgress polls egressID until it reaches a terminal status, returning an
+// error unless it completed.
+func awaitEgress(ctx context.Context, egressID string, interval, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var (
+		lastStatus livekit.EgressStatus
+		seen       bool
+	)
+	for {
+		res, err := egressClient.ListEgress(ctx, &livekit.ListEgressRequest{EgressId: egressID})
+		if err != nil && ctx.Err() == nil {
+			return err
+		}
+		if res != nil {
+			if len(res.Items) == 0 {
+				return fmt.Errorf("egress %s not found", egressID)
+			}
+			info := res.Items[0]
+			if !seen || info.Status != lastStatus {
+				fmt.Println("Egress status:", info.Status)
+				lastStatus, seen = info.Status, true
+			}
+			switch info.Status {
+			case livekit.EgressStatus_EGRESS_COMPLETE, livekit.EgressStatus_EGRESS_LIMIT_REACHED:
+				return nil
+			case livekit.EgressStatus_EGRESS_FAILED, livekit.EgressStatus_EGRESS_ABORTED:
+				if info.Error != "" {
+					return fmt.Errorf("egress %s ended with %s: %s", egressID, info.Status, info.Error)
+				}
+				return fmt.Errorf("egress %s ended with %s", egressID, info.Status)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				if !seen {
+					return fmt.Errorf("egress %s did not complete within %s", egressID, timeout)
+				}
+				return fmt.Errorf("egress %s did not complete within %s (last status: %s)", egressID, timeout, lastStatus)
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
 func testEgressTemplate(ctx context.Context, cmd *cli.Command) error {
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
 
 	numPublishers := int(cmd.Int("publishers"))
+	speakerInterval := cmd.Duration("speaker-interval")
+	if cmd.IsSet("speaker-interval") && speakerInterval < time.Second {
+		return errors.New("speaker-interval must be at least 1s")
+	}
+	videoQuality := cmd.String("publishers-video-quality")
+	switch videoQuality {
+	case "":
+		videoQuality = "high"
+	case "high", "medium", "low":
+	default:
+		return fmt.Errorf("invalid publishers-video-quality %q, expected high, medium or low", videoQuality)
+	}
+
 	rooms := make([]*lksdk.Room, 0, numPublishers)
 	defer func() {
 		for _, room := range rooms {
@@ -745,7 +1244,7 @@ func testEgressTemplate(ctx context.Context, cmd *cli.Command) error {
 		}
 
 		testers = append(testers, lt)
-		if _, err = lt.PublishSimulcastTrack("demo-video", "high", ""); err != nil {
+		if _, err = lt.PublishSimulcastTrack("demo-video", videoQuality, ""); err != nil {
 			return err
 		}
 	}
@@ -755,16 +1254,15 @@ func testEgressTemplate(ctx context.Context, cmd *cli.Command) error {
 		return err
 	}
 
-	templateURL := fmt.Sprintf(
-		"%s/?url=%s&layout=%s&token=%s",
-		cmd.String("base-url"), url.QueryEscape(serverURL), cmd.String("layout"), token,
-	)
+	templateURL := egressTemplateURL(cmd.String("base-url"), serverURL, cmd.String("layout"), token)
 	if err := browser.OpenURL(templateURL); err != nil {
 		return err
 	}
 
 	sim := loadtester.NewSpeakerSimulator(loadtester.SpeakerSimulatorParams{
-		Testers: testers,
+		Testers:        testers,
+		Pause:          uint64(speakerInterval / time.Second),
+		ActiveSpeakers: int(cmd.Int("active-speakers")),
 	})
 	sim.Start()
 	fmt.Println("simulating speakers...")
@@ -778,6 +1276,170 @@ func testEgressTemplate(ctx context.Context, cmd *cli.Command) error {
 	return nil
 }
 
+// egressTemplateURL is the address of a layout template page that joins the
+// room on serverURL with token, as room composite egress loads it.
+func egressTemplateURL(baseURL, serverURL, layout, token string) string {
+	return fmt.Sprintf(
+		"%s/?url=%s&layout=%s&token=%s",
+		strings.TrimSuffix(baseURL, "/"), url.QueryEscape(serverURL), url.QueryEscape(layout), token,
+	)
+}
+
+// previewEgress saves a screenshot of the page a room-composite or web egress
+// would record. Room composites are rendered with the same template URL and
+// token that test-template uses.
+func previewEgress(ctx context.Context, cmd *cli.Command, typ egressType, reqBytes []byte, file string) error {
+	var pageURL string
+	var width, height int32
+	switch typ {
+	case EgressTypeRoomComposite:
+		req := &livekit.RoomCompositeEgressRequest{}
+		if err := unmarshaller.Unmarshal(reqBytes, req); err != nil {
+			return err
+		}
+		pc, err := loadProjectDetails(cmd)
+		if err != nil {
+			return err
+		}
+		token, err := egress.BuildEgressToken("template_preview", pc.APIKey, pc.APISecret, req.RoomName)
+		if err != nil {
+			return err
+		}
+		baseURL := req.CustomBaseUrl
+		if baseURL == "" {
+			baseURL = defaultEgressTemplateURL
+		}
+		pageURL = egressTemplateURL(baseURL, pc.URL, req.Layout, token)
+		width, height = egressDimensions(req.GetPreset(), req.GetAdvanced())
+	case EgressTypeWeb:
+		req := &livekit.WebEgressRequest{}
+		if err := unmarshaller.Unmarshal(reqBytes, req); err != nil {
+			return err
+		}
+		pageURL = req.Url
+		width, height = egressDimensions(req.GetPreset(), req.GetAdvanced())
+	default:
+		return fmt.Errorf("--preview is only supported for %s and %s egress", EgressTypeRoomComposite, EgressTypeWeb)
+	}
+
+	if cmd.Bool("verbose") {
+		fmt.Println("Rendering", pageURL)
+	}
+	if err := captureScreenshot(ctx, pageURL, file, width, height); err != nil {
+		return err
+	}
+	fmt.Printf("Saved %dx%d preview to %s\n", width, height, file)
+	return nil
+}
+
+// egressDimensions returns the output size of an egress, which is also the
+// size of the page it records.
+func egressDimensions(preset livekit.EncodingOptionsPreset, advanced *livekit.EncodingOptions) (int32, int32) {
+	if advanced != nil && advanced.Width > 0 && advanced.Height > 0 {
+		return advanced.Width, advanced.Height
+	}
+	width, height := int32(1280), int32(720)
+	name := preset.String()
+	if strings.Contains(name, "1080P") {
+		width, height = 1920, 1080
+	}
+	if strings.HasPrefix(name, "PORTRAIT_") {
+		width, height = height, width
+	}
+	return width, height
+}
+
+// printOutputLocations prints where each file and HLS playlist will be available.
+// When the storage provider and path are known up front, this is a URL which can
+// be shared right away, otherwise it's the path as configured.
+func printOutputLocations(files []*livekit.EncodedFileOutput, segments []*livekit.SegmentedFileOutput) {
+	for _, f := range files {
+		loc := f.Filepath
+		if u, ok := storageURL(f.GetS3(), f.GetGcp(), f.GetAzure(), f.GetAliOSS(), loc); ok {
+			loc = u
+		}
+		if loc != "" {
+			fmt.Println("File:", loc)
+		}
+	}
+	for _, seg := range segments {
+		loc := playlistPath(seg)
+		if u, ok := storageURL(seg.GetS3(), seg.GetGcp(), seg.GetAzure(), seg.GetAliOSS(), loc); ok {
+			loc = u
+		}
+		if loc != "" {
+			fmt.Println("Playlist:", loc)
+		}
+	}
+}
+
+func playlistPath(seg *livekit.SegmentedFileOutput) string {
+	name := seg.PlaylistName
+	if name == "" {
+		return seg.FilenamePrefix
+	}
+	if !strings.HasSuffix(name, ".m3u8") {
+		name += ".m3u8"
+	}
+	// playlists without a directory are written next to the segments
+	if !strings.Contains(name, "/") {
+		if dir := path.Dir(seg.FilenamePrefix); dir != "." {
+			name = path.Join(dir, name)
+		}
+	}
+	return name
+}
+
+func storageURL(s3 *livekit.S3Upload, gcp *livekit.GCPUpload, azure *livekit.AzureBlobUpload, ali *livekit.AliOSSUpload, key string) (string, bool) {
+	// templated paths such as {room_name} or {time} are only resolved by the egress
+	if key == "" || strings.Contains(key, "{") {
+		return "", false
+	}
+	key = strings.TrimPrefix(key, "/")
+
+	switch {
+	case s3 != nil:
+		if s3.Endpoint != "" {
+			endpoint, err := url.Parse(s3.Endpoint)
+			if err != nil || endpoint.Host == "" {
+				return "", false
+			}
+			if s3.ForcePathStyle {
+				return endpoint.JoinPath(s3.Bucket, key).String(), true
+			}
+			endpoint.Host = s3.Bucket + "." + endpoint.Host
+			return endpoint.JoinPath(key).String(), true
+		}
+		if s3.Region == "" {
+			return fmt.Sprintf("https://%s.s3.amazonaws.com/%s", s3.Bucket, key), true
+		}
+		return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s3.Bucket, s3.Region, key), true
+	case gcp != nil:
+		return fmt.Sprintf("https://storage.googleapis.com/%s/%s", gcp.Bucket, key), true
+	case azure != nil:
+		return fmt.Sprintf("https://%s.blob.core.windows.net/%s/%s", azure.AccountName, azure.ContainerName, key), true
+	case ali != nil:
+		if ali.Endpoint != "" {
+			endpoint, err := url.Parse(ali.Endpoint)
+			if err != nil || endpoint.Host == "" {
+				return "", false
+			}
+			endpoint.Host = ali.Bucket + "." + endpoint.Host
+			return endpoint.JoinPath(key).String(), true
+		}
+		return fmt.Sprintf("https://%s.oss-%s.aliyuncs.com/%s", ali.Bucket, ali.Region, key), true
+	default:
+		return "", false
+	}
+}
+
+func appendNonNil[T any](items []*T, item *T) []*T {
+	if item != nil {
+		return append(items, item)
+	}
+	return items
+}
+
 func printInfo(info *livekit.EgressInfo) {
 	if info.Error == "" {
 		fmt.Printf("EgressID: %v Status: %v\n", info.EgressId, info.Status)
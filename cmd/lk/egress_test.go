@@ -0,0 +1,107 @@
+// Copyright 2024 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+func TestInferEgressType(t *testing.T) {
+	for _, tc := range []struct {
+		req      string
+		expected egressType
+	}{
+		{`{"room_name": "my-room", "layout": "speaker", "file_outputs": [{"filepath": "out.mp4"}]}`, EgressTypeRoomComposite},
+		{`{"roomName": "my-room", "file_outputs": [{"filepath": "out.mp4"}]}`, EgressTypeRoomComposite},
+		{`{"room_name": "my-room", "identity": "alice", "screen_share": true}`, EgressTypeParticipant},
+		{`{"room_name": "my-room", "track_id": "TR_XXX", "websocket_url": "wss://example.com"}`, EgressTypeTrack},
+		{`{"room_name": "my-room", "audio_track_id": "TR_XXX", "video_track_id": "TR_YYY"}`, EgressTypeTrackComposite},
+		{`{"url": "https://example.com", "audio_only": true}`, EgressTypeWeb},
+	} {
+		typ, err := inferEgressType([]byte(tc.req))
+		require.NoError(t, err, tc.req)
+		assert.Equal(t, tc.expected, typ, tc.req)
+	}
+
+	_, err := inferEgressType([]byte(`{"file_outputs": [{"filepath": "out.mp4"}]}`))
+	assert.Error(t, err, "Expected an error when no type has its required fields")
+
+	_, err = inferEgressType([]byte(`{"room_name": "my-room", "unknown_field": true}`))
+	assert.Error(t, err, "Expected an error for unknown fields")
+}
+
+func TestStorageURL(t *testing.T) {
+	u, ok := storageURL(&livekit.S3Upload{Bucket: "b", Region: "us-west-2"}, nil, nil, nil, "hls/playlist.m3u8")
+	require.True(t, ok)
+	assert.Equal(t, "https://b.s3.us-west-2.amazonaws.com/hls/playlist.m3u8", u)
+
+	u, ok = storageURL(&livekit.S3Upload{Bucket: "b", Endpoint: "https://minio.local:9000", ForcePathStyle: true}, nil, nil, nil, "out.mp4")
+	require.True(t, ok)
+	assert.Equal(t, "https://minio.local:9000/b/out.mp4", u)
+
+	u, ok = storageURL(nil, &livekit.GCPUpload{Bucket: "b"}, nil, nil, "/out.mp4")
+	require.True(t, ok)
+	assert.Equal(t, "https://storage.googleapis.com/b/out.mp4", u)
+
+	_, ok = storageURL(nil, &livekit.GCPUpload{Bucket: "b"}, nil, nil, "{room_name}/out.mp4")
+	assert.False(t, ok, "Templated paths can't be resolved ahead of time")
+
+	_, ok = storageURL(nil, nil, nil, nil, "out.mp4")
+	assert.False(t, ok, "Default storage has no known URL")
+
+	assert.Equal(t, "hls/playlist.m3u8", playlistPath(&livekit.SegmentedFileOutput{
+		FilenamePrefix: "hls/segment",
+		PlaylistName:   "playlist",
+	}))
+}
+
+func TestValidateStreamURL(t *testing.T) {
+	for _, u := range []string{
+		"rtmp://live.example.com/app/key",
+		"RTMPS://live.example.com:443/app",
+		"srt://10.0.0.1:9000?streamid=abc",
+		"srts://example.com:9000",
+	} {
+		assert.NoError(t, validateStreamURL(u), u)
+	}
+	for _, u := range []string{
+		"rtmp:/live.example.com/app",
+		"https://example.com/app",
+		"live.example.com/app",
+		"rtmp://",
+	} {
+		assert.Error(t, validateStreamURL(u), u)
+	}
+}
+
+func TestSummarizeEgress(t *testing.T) {
+	roomComposite := func(room string) *livekit.EgressInfo {
+		return &livekit.EgressInfo{
+			RoomName: room,
+			Request:  &livekit.EgressInfo_RoomComposite{RoomComposite: &livekit.RoomCompositeEgressRequest{RoomName: room}},
+		}
+	}
+	summary := summarizeEgress([]*livekit.EgressInfo{roomComposite("a"), roomComposite("b"), roomComposite("b")})
+
+	assert.Equal(t, 3, summary.Total)
+	require.Len(t, summary.Groups, 2)
+	assert.Equal(t, egressCount{Room: "b", Type: "room_composite", Count: 2}, *summary.Groups[0])
+	assert.Equal(t, egressCount{Room: "a", Type: "room_composite", Count: 1}, *summary.Groups[1])
+}
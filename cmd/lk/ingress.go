@@ -16,12 +16,20 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
 
+	"github.com/charmbracelet/huh"
 	"github.com/urfave/cli/v3"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/livekit/livekit-cli/pkg/util"
 	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/utils/guid"
 	lksdk "github.com/livekit/server-sdk-go/v2"
 )
 
@@ -50,10 +58,13 @@ var (
 				{
 					Name:      "update",
 					Usage:     "Update an ingress",
-					UsageText: "lk ingress update [OPTIONS] JSON",
-					ArgsUsage: "JSON",
-					Before:    createIngressClient,
-					Action:    updateIngress,
+					UsageText: "lk ingress update [OPTIONS] ID | JSON",
+					ArgsUsage: "ID | JSON",
+					Description: "Updates the ingress with the given ID using the fields set by flags, or applies an\n" +
+						"UpdateIngressRequest given as a JSON file or literal. Flags override fields in the JSON.\n" +
+						"Only fields that are set are changed.",
+					Before: createIngressClient,
+					Action: updateIngress,
 					Flags: []cli.Flag{
 						&cli.StringFlag{
 							Hidden:    true, // deprecated: use ARG0
@@ -61,14 +72,41 @@ var (
 							Usage:     "UpdateIngressRequest as json file (see cmd/lk/examples)",
 							TakesFile: true,
 						},
+						&cli.StringFlag{
+							Name:  "name",
+							Usage: "New `NAME` of the ingress",
+						},
+						&cli.StringFlag{
+							Name:  "room",
+							Usage: "`NAME` of the room to publish to",
+						},
+						&cli.StringFlag{
+							Name:  "participant-identity",
+							Usage: "`IDENTITY` of the participant publishing the ingress",
+						},
+						&cli.StringFlag{
+							Name:  "participant-name",
+							Usage: "Display `NAME` of the participant publishing the ingress",
+						},
+						&cli.StringFlag{
+							Name:  "participant-metadata",
+							Usage: "`METADATA` of the participant publishing the ingress",
+						},
+						&cli.BoolFlag{
+							Name:  "bypass-transcoding",
+							Usage: "Forward the input without transcoding (WHIP only)",
+						},
+						&cli.BoolFlag{
+							Name:  "enable-transcoding",
+							Usage: "Transcode the input, use --enable-transcoding=false to disable it",
+						},
 					},
 				},
 				{
 					Name:      "list",
 					Usage:     "List all active ingress",
 					UsageText: "lk ingress list [OPTIONS]",
-					Before:    createIngressClient,
-					Action:    listIngress,
+					Action:    forEachProject(createIngressClient, listIngress),
 					Flags: []cli.Flag{
 						&cli.StringFlag{
 							Name:     "room",
@@ -85,11 +123,18 @@ var (
 				},
 				{
 					Name:      "delete",
-					Usage:     "Delete an ingress",
-					UsageText: "lk ingress delete ID",
-					ArgsUsage: "ID",
+					Usage:     "Delete one or more ingresses",
+					UsageText: "lk ingress delete [OPTIONS] ID [ID...]",
+					ArgsUsage: "ID [ID...]",
 					Before:    createIngressClient,
 					Action:    deleteIngress,
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:    "yes",
+							Aliases: []string{"y"},
+							Usage:   "Skip the confirmation prompt",
+						},
+					},
 				},
 			},
 		},
@@ -128,7 +173,7 @@ var (
 			Name:   "list-ingress",
 			Usage:  "List all active ingress",
 			Before: createIngressClient,
-			Action: listIngress,
+			Action: printListing(listIngress),
 			Flags: []cli.Flag{
 				&cli.StringFlag{
 					Name:     "room",
@@ -167,7 +212,7 @@ func createIngressClient(ctx context.Context, cmd *cli.Command) (context.Context
 		return nil, err
 	}
 
-	ingressClient = lksdk.NewIngressClient(pc.URL, pc.APIKey, pc.APISecret, withDefaultClientOpts(pc)...)
+	ingressClient = lksdk.NewIngressClient(pc.APIURL(), pc.APIKey, pc.APISecret, withDefaultClientOpts(pc)...)
 	return nil, nil
 }
 
@@ -176,9 +221,12 @@ func createIngress(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return err
 	}
+	if err = validateIngressURL(req); err != nil {
+		return err
+	}
 
 	if cmd.Bool("verbose") {
-		util.PrintJSON(req)
+		util.PrintJSONRedacted(req)
 	}
 
 	info, err := ingressClient.CreateIngress(context.Background(), req)
@@ -191,13 +239,28 @@ func createIngress(ctx context.Context, cmd *cli.Command) error {
 }
 
 func updateIngress(ctx context.Context, cmd *cli.Command) error {
-	req, err := ReadRequestArgOrFlag[livekit.UpdateIngressRequest](cmd)
-	if err != nil {
+	var req *livekit.UpdateIngressRequest
+	arg := cmd.Args().First()
+	// a JSON file may be named after the ingress it updates, so files win
+	_, statErr := os.Stat(arg)
+	byID := strings.HasPrefix(arg, guid.IngressPrefix) && statErr != nil
+	if byID {
+		req = &livekit.UpdateIngressRequest{IngressId: arg}
+	} else {
+		var err error
+		if req, err = ReadRequestArgOrFlag[livekit.UpdateIngressRequest](cmd); err != nil {
+			return err
+		}
+	}
+	if req.IngressId == "" {
+		return errors.New("ingress ID is required")
+	}
+	if err := applyIngressUpdateFlags(cmd, req, byID); err != nil {
 		return err
 	}
 
 	if cmd.Bool("verbose") {
-		util.PrintJSON(req)
+		util.PrintJSONRedacted(req)
 	}
 
 	info, err := ingressClient.UpdateIngress(context.Background(), req)
@@ -209,21 +272,88 @@ func updateIngress(ctx context.Context, cmd *cli.Command) error {
 	return nil
 }
 
-func listIngress(ctx context.Context, cmd *cli.Command) error {
+var ingressURLSchemes = []string{"http", "https", "srt"}
+
+// validateIngressURL checks that URL_INPUT ingresses have a well-formed source
+// url, trimming surrounding whitespace, and that push ingresses don't set one
+// as the server allocates it.
+func validateIngressURL(req *livekit.CreateIngressRequest) error {
+	req.Url = strings.TrimSpace(req.Url)
+	if req.InputType != livekit.IngressInput_URL_INPUT {
+		if req.Url != "" {
+			return fmt.Errorf("url must not be set for %s ingresses, the server allocates it", req.InputType)
+		}
+		return nil
+	}
+
+	if req.Url == "" {
+		return errors.New("url is required for URL_INPUT ingresses")
+	}
+	u, err := url.Parse(req.Url)
+	if err != nil {
+		return fmt.Errorf("invalid url %q: %w", req.Url, err)
+	}
+	if !slices.Contains(ingressURLSchemes, strings.ToLower(u.Scheme)) {
+		return fmt.Errorf("invalid url %q: scheme must be one of %s", req.Url, strings.Join(ingressURLSchemes, ", "))
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid url %q: missing host", req.Url)
+	}
+	return nil
+}
+
+// applyIngressUpdateFlags sets the fields of req given by flags. When
+// requireUpdate is set, at least one flag must be given.
+func applyIngressUpdateFlags(cmd *cli.Command, req *livekit.UpdateIngressRequest, requireUpdate bool) error {
+	updated := false
+	for flag, field := range map[string]*string{
+		"name":                 &req.Name,
+		"room":                 &req.RoomName,
+		"participant-identity": &req.ParticipantIdentity,
+		"participant-name":     &req.ParticipantName,
+		"participant-metadata": &req.ParticipantMetadata,
+	} {
+		if cmd.IsSet(flag) {
+			*field = cmd.String(flag)
+			updated = true
+		}
+	}
+	if cmd.IsSet("bypass-transcoding") && cmd.IsSet("enable-transcoding") &&
+		cmd.Bool("bypass-transcoding") == cmd.Bool("enable-transcoding") {
+		return errors.New("--bypass-transcoding and --enable-transcoding conflict, set only one of them")
+	}
+	if cmd.IsSet("bypass-transcoding") {
+		req.BypassTranscoding = proto.Bool(cmd.Bool("bypass-transcoding"))
+		updated = true
+	}
+	if cmd.IsSet("enable-transcoding") {
+		req.EnableTranscoding = proto.Bool(cmd.Bool("enable-transcoding"))
+		updated = true
+	}
+	if requireUpdate && !updated {
+		return errors.New("nothing to update, set at least one of --name, --room, --participant-identity, --participant-name, --participant-metadata, --bypass-transcoding or --enable-transcoding")
+	}
+	return nil
+}
+
+func listIngress(ctx context.Context, cmd *cli.Command) (*listing, error) {
 	res, err := ingressClient.ListIngress(context.Background(), &livekit.ListIngressRequest{
 		RoomName:  cmd.String("room"),
 		IngressId: cmd.String("id"),
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// NOTE: previously, the `verbose` flag was used to output JSON in addition to the table.
-	// This is inconsistent with other commands in which verbose is used for debug info, but is
-	// kept for compatibility with the previous behavior.
-	if cmd.Bool("verbose") || cmd.Bool("json") {
-		util.PrintJSON(res)
-	} else {
+	return &listing{data: res, print: func() error {
+		// NOTE: previously, the `verbose` flag was used to output JSON in addition to the table.
+		// This is inconsistent with other commands in which verbose is used for debug info, but is
+		// kept for compatibility with the previous behavior.
+		if cmd.Bool("verbose") || structuredOutput(cmd) {
+			printOutput(res)
+			return nil
+		}
+
 		table := util.CreateTable().
 			Headers("IngressID", "Name", "Room", "StreamKey", "URL", "Status", "Error")
 		for _, item := range res.Items {
@@ -247,25 +377,57 @@ func listIngress(ctx context.Context, cmd *cli.Command) error {
 				errorStr,
 			)
 		}
-		fmt.Println(table)
-	}
-
-	return nil
+		return printTable(table)
+	}}, nil
 }
 
 func deleteIngress(ctx context.Context, cmd *cli.Command) error {
-	id := cmd.String("id")
-	if id == "" {
-		id = cmd.Args().First()
+	// deprecated: `delete-ingress --id ID`
+	if id := cmd.String("id"); id != "" {
+		info, err := ingressClient.DeleteIngress(ctx, &livekit.DeleteIngressRequest{
+			IngressId: id,
+		})
+		if err != nil {
+			return err
+		}
+		printIngressInfo(info)
+		return nil
 	}
-	info, err := ingressClient.DeleteIngress(ctx, &livekit.DeleteIngressRequest{
-		IngressId: id,
-	})
-	if err != nil {
-		return err
+
+	ids := cmd.Args().Slice()
+	if len(ids) == 0 {
+		return errors.New("at least one ID is required")
+	}
+	if !cmd.Bool("yes") {
+		confirmed := false
+		if err := huh.NewConfirm().
+			Title(fmt.Sprintf("Delete %d ingress(es)? %s", len(ids), strings.Join(ids, ", "))).
+			Value(&confirmed).
+			Inline(true).
+			WithTheme(util.Theme).
+			Run(); err != nil {
+			return err
+		}
+		if !confirmed {
+			return errors.New("aborted")
+		}
 	}
 
-	printIngressInfo(info)
+	var errs []error
+	_ = forEachID(ctx, cmd, func(ctx context.Context, id string) error {
+		if _, err := ingressClient.DeleteIngress(ctx, &livekit.DeleteIngressRequest{
+			IngressId: id,
+		}); err != nil {
+			fmt.Printf("IngressID: %v Error: %v\n", id, err)
+			errs = append(errs, fmt.Errorf("%s: %w", id, err))
+			return nil
+		}
+		fmt.Printf("IngressID: %v Deleted\n", id)
+		return nil
+	})
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to delete %d of %d ingress(es): %w", len(errs), len(ids), errors.Join(errs...))
+	}
 	return nil
 }
 
@@ -0,0 +1,112 @@
+// Copyright 2024 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+func TestValidateIngressURL(t *testing.T) {
+	req := &livekit.CreateIngressRequest{InputType: livekit.IngressInput_URL_INPUT, Url: " https://example.com/live.m3u8\n"}
+	require.NoError(t, validateIngressURL(req))
+	assert.Equal(t, "https://example.com/live.m3u8", req.Url)
+
+	for _, u := range []string{"", "htps://example.com/a.mp4", "example.com/a.mp4", "https:///a.mp4"} {
+		err := validateIngressURL(&livekit.CreateIngressRequest{InputType: livekit.IngressInput_URL_INPUT, Url: u})
+		assert.Error(t, err, u)
+	}
+
+	require.NoError(t, validateIngressURL(&livekit.CreateIngressRequest{InputType: livekit.IngressInput_WHIP_INPUT}))
+	assert.Error(t, validateIngressURL(&livekit.CreateIngressRequest{InputType: livekit.IngressInput_RTMP_INPUT, Url: "rtmp://example.com/live"}))
+}
+
+func TestApplyIngressUpdateFlags(t *testing.T) {
+	run := func(requireUpdate bool, args ...string) (*livekit.UpdateIngressRequest, error) {
+		req := &livekit.UpdateIngressRequest{IngressId: "IN_test", Name: "old"}
+		var err error
+		cmd := &cli.Command{
+			Name: "update",
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "name"},
+				&cli.StringFlag{Name: "room"},
+				&cli.StringFlag{Name: "participant-identity"},
+				&cli.StringFlag{Name: "participant-name"},
+				&cli.StringFlag{Name: "participant-metadata"},
+				&cli.BoolFlag{Name: "bypass-transcoding"},
+				&cli.BoolFlag{Name: "enable-transcoding"},
+			},
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				err = applyIngressUpdateFlags(cmd, req, requireUpdate)
+				return nil
+			},
+		}
+		require.NoError(t, cmd.Run(context.Background(), append([]string{"update"}, args...)))
+		return req, err
+	}
+
+	req, err := run(true, "--room", "lobby", "--enable-transcoding=false")
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(&livekit.UpdateIngressRequest{
+		IngressId:         "IN_test",
+		Name:              "old",
+		RoomName:          "lobby",
+		EnableTranscoding: proto.Bool(false),
+	}, req), req.String())
+
+	req, err = run(true, "--bypass-transcoding", "--enable-transcoding=false")
+	require.NoError(t, err)
+	assert.True(t, req.GetBypassTranscoding())
+	assert.False(t, req.GetEnableTranscoding())
+
+	_, err = run(true)
+	assert.ErrorContains(t, err, "nothing to update")
+
+	req, err = run(false)
+	require.NoError(t, err, "flags are optional when updating from JSON")
+	assert.Equal(t, "old", req.Name)
+
+	for _, args := range [][]string{
+		{"--bypass-transcoding", "--enable-transcoding"},
+		{"--bypass-transcoding=false", "--enable-transcoding=false"},
+	} {
+		_, err = run(true, args...)
+		assert.ErrorContains(t, err, "conflict", args)
+	}
+}
+
+func TestUpdateIngressFileNamedAfterID(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "IN_test.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{"ingress_id": ""}`), 0600))
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	defer os.Chdir(wd)
+
+	cmd := &cli.Command{Name: "update", Action: updateIngress}
+	err = cmd.Run(context.Background(), []string{"update", "IN_test.json"})
+	assert.EqualError(t, err, "ingress ID is required", "the file should be read, not taken as an ID")
+}
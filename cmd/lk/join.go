@@ -16,10 +16,12 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"os"
+	"os/exec"
 	"os/signal"
 	"path/filepath"
 	"strings"
@@ -71,7 +73,13 @@ var (
 	}
 )
 
-const mimeDelimiter = "://"
+const (
+	mimeDelimiter = "://"
+	sourcePrefix  = "source="
+
+	// maxPublishTracks caps how many files a single glob may expand to
+	maxPublishTracks = 32
+)
 
 func _deprecatedJoinRoom(ctx context.Context, cmd *cli.Command) error {
 	pc, err := loadProjectDetails(cmd)
@@ -144,7 +152,7 @@ func _deprecatedJoinRoom(ctx context.Context, cmd *cli.Command) error {
 			close(done)
 		},
 	}
-	room, err := lksdk.ConnectToRoom(pc.URL, lksdk.ConnectInfo{
+	room, err := lksdk.ConnectToRoom(pc.APIURL(), lksdk.ConnectInfo{
 		APIKey:              pc.APIKey,
 		APISecret:           pc.APISecret,
 		RoomName:            cmd.String("room"),
@@ -193,14 +201,86 @@ func handlePublish(room *lksdk.Room,
 	fps float64,
 	onPublishComplete func(pub *lksdk.LocalTrackPublication),
 ) error {
+	source, name, err := parseSourceFromName(name)
+	if err != nil {
+		return err
+	}
 	if isSocketFormat(name) {
 		mimeType, socketType, address, err := parseSocketFromName(name)
 		if err != nil {
 			return err
 		}
-		return publishSocket(room, mimeType, socketType, address, fps, onPublishComplete)
+		return publishSocket(room, mimeType, socketType, address, fps, source, onPublishComplete)
 	}
-	return publishFile(room, name, fps, onPublishComplete)
+	return publishFile(room, name, fps, source, onPublishComplete)
+}
+
+// Expand glob patterns in publish entries so that each matching file is
+// published as its own track. Entries the shell already expanded, and socket
+// entries, are passed through unchanged.
+// e.g. './clips/*.ivf'
+// e.g. 'source=screen_share:./clips/*.h264'
+func expandPublishGlobs(entries []string) ([]string, error) {
+	var expanded []string
+	for _, entry := range entries {
+		if isSocketFormat(entry) {
+			expanded = append(expanded, entry)
+			continue
+		}
+		prefix := ""
+		name := entry
+		if rest, ok := strings.CutPrefix(entry, sourcePrefix); ok {
+			if src, file, ok := strings.Cut(rest, ":"); ok {
+				prefix = sourcePrefix + src + ":"
+				name = file
+			}
+		}
+		if !strings.ContainsAny(name, "*?[") {
+			expanded = append(expanded, entry)
+			continue
+		}
+		if _, err := os.Stat(name); err == nil {
+			// a file literally named like a pattern
+			expanded = append(expanded, entry)
+			continue
+		}
+
+		matches, err := filepath.Glob(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %s: %w", name, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %s", name)
+		}
+		if len(matches) > maxPublishTracks {
+			logger.Warnw("too many files match pattern, publishing the first ones only", nil,
+				"pattern", name, "matches", len(matches), "limit", maxPublishTracks)
+			matches = matches[:maxPublishTracks]
+		}
+		for _, m := range matches {
+			expanded = append(expanded, prefix+m)
+		}
+	}
+	return expanded, nil
+}
+
+// Strip an optional source prefix from a publish entry
+// e.g. source=screen_share:video.h264
+// e.g. source=microphone:opus:///tmp/my.socket
+func parseSourceFromName(name string) (livekit.TrackSource, string, error) {
+	rest, ok := strings.CutPrefix(name, sourcePrefix)
+	if !ok {
+		return livekit.TrackSource_UNKNOWN, name, nil
+	}
+	src, rest, ok := strings.Cut(rest, ":")
+	if !ok || rest == "" {
+		return livekit.TrackSource_UNKNOWN, "", fmt.Errorf("expected %s<source>:<file> in %s", sourcePrefix, name)
+	}
+	source, err := parseTrackSource(src)
+	if err != nil {
+		return livekit.TrackSource_UNKNOWN, "", err
+	}
+	return source, rest, nil
 }
 
 func publishDemo(room *lksdk.Room) error {
@@ -232,6 +312,7 @@ func publishDemo(room *lksdk.Room) error {
 func publishFile(room *lksdk.Room,
 	filename string,
 	fps float64,
+	source livekit.TrackSource,
 	onPublishComplete func(pub *lksdk.LocalTrackPublication),
 ) error {
 	// Configure provider
@@ -265,11 +346,48 @@ func publishFile(room *lksdk.Room,
 		return err
 	}
 	pub, err = room.LocalParticipant.PublishTrack(track, &lksdk.TrackPublicationOptions{
-		Name: filename,
+		Name:   filename,
+		Source: source,
 	})
 	return err
 }
 
+// publishMicrophone captures the default audio input device with ffmpeg and
+// publishes it as an Opus track until ctx is cancelled.
+func publishMicrophone(ctx context.Context, room *lksdk.Room) error {
+	input, err := micInputArgs()
+	if err != nil {
+		return err
+	}
+	ffmpeg, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return errors.New("--publish-mic requires ffmpeg to be installed and on your PATH")
+	}
+
+	args := append([]string{"-hide_banner", "-loglevel", "error"}, input...)
+	args = append(args,
+		"-c:a", "libopus",
+		"-page_duration", "20000", // flush an Ogg page per Opus frame to keep latency low
+		"-f", "ogg", "-",
+	)
+	capture := exec.CommandContext(ctx, ffmpeg, args...)
+	capture.Stderr = os.Stderr
+	out, err := capture.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err = capture.Start(); err != nil {
+		return fmt.Errorf("could not start audio capture: %w", err)
+	}
+	go func() {
+		if err := capture.Wait(); err != nil && ctx.Err() == nil {
+			logger.Warnw("audio capture stopped", err)
+		}
+	}()
+
+	return publishReader(room, out, webrtc.MimeTypeOpus, 0, livekit.TrackSource_MICROPHONE, nil)
+}
+
 func parseSocketFromName(name string) (string, string, string, error) {
 	// Extract mime type, socket type, and address
 	// e.g. h264://192.168.0.1:1234 (tcp)
@@ -309,6 +427,7 @@ func publishSocket(room *lksdk.Room,
 	socketType string,
 	address string,
 	fps float64,
+	source livekit.TrackSource,
 	onPublishComplete func(pub *lksdk.LocalTrackPublication),
 ) error {
 	var mime string
@@ -330,7 +449,7 @@ func publishSocket(room *lksdk.Room,
 	}
 
 	// Publish to room
-	err = publishReader(room, sock, mime, fps, onPublishComplete)
+	err = publishReader(room, sock, mime, fps, source, onPublishComplete)
 	return err
 }
 
@@ -338,6 +457,7 @@ func publishReader(room *lksdk.Room,
 	in io.ReadCloser,
 	mime string,
 	fps float64,
+	source livekit.TrackSource,
 	onPublishComplete func(pub *lksdk.LocalTrackPublication),
 ) error {
 	// Configure provider
@@ -362,7 +482,9 @@ func publishReader(room *lksdk.Room,
 	if err != nil {
 		return err
 	}
-	pub, err = room.LocalParticipant.PublishTrack(track, &lksdk.TrackPublicationOptions{})
+	pub, err = room.LocalParticipant.PublishTrack(track, &lksdk.TrackPublicationOptions{
+		Source: source,
+	})
 	if err != nil {
 		return err
 	}
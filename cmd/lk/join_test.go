@@ -15,9 +15,13 @@
 package main
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/livekit/protocol/livekit"
 )
 
 func TestSocketFormat(t *testing.T) {
@@ -83,3 +87,51 @@ func TestParseSocketString(t *testing.T) {
 	assert.Equal(t, address, "foobar.com:1234")
 	assert.Equal(t, err, nil, "Expected no error for valid vp8 TCP socket")
 }
+
+func TestParseSourceFromName(t *testing.T) {
+	source, name, err := parseSourceFromName("video.h264")
+	assert.NoError(t, err)
+	assert.Equal(t, livekit.TrackSource_UNKNOWN, source)
+	assert.Equal(t, "video.h264", name)
+
+	source, name, err = parseSourceFromName("source=screenshare:video.h264")
+	assert.NoError(t, err)
+	assert.Equal(t, livekit.TrackSource_SCREEN_SHARE, source)
+	assert.Equal(t, "video.h264", name)
+
+	source, name, err = parseSourceFromName("source=screenshare_audio:opus://foobar.com:1234")
+	assert.NoError(t, err)
+	assert.Equal(t, livekit.TrackSource_SCREEN_SHARE_AUDIO, source)
+	assert.Equal(t, "opus://foobar.com:1234", name)
+
+	_, _, err = parseSourceFromName("source=camera")
+	assert.Error(t, err, "Expected an error for missing file")
+
+	_, _, err = parseSourceFromName("source=window:video.h264")
+	assert.Error(t, err, "Expected an error for invalid source")
+}
+
+func TestExpandPublishGlobs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.ivf", "b.ivf", "c.ogg"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0644))
+	}
+
+	expanded, err := expandPublishGlobs([]string{
+		filepath.Join(dir, "*.ivf"),
+		"source=screen_share:" + filepath.Join(dir, "*.ogg"),
+		"opus:///tmp/*.sock",
+		"plain.h264",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "a.ivf"),
+		filepath.Join(dir, "b.ivf"),
+		"source=screen_share:" + filepath.Join(dir, "c.ogg"),
+		"opus:///tmp/*.sock",
+		"plain.h264",
+	}, expanded)
+
+	_, err = expandPublishGlobs([]string{filepath.Join(dir, "*.h264")})
+	assert.Error(t, err, "Expected an error when nothing matches")
+}
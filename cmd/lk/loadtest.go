@@ -16,6 +16,8 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"os"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -41,6 +43,11 @@ var LoadTestCommands = []*cli.Command{
 				Usage: "`TIME` duration to run, 1m, 1h (by default will run until canceled)",
 				Value: 0,
 			},
+			&cli.DurationFlag{
+				Name:  "max-duration",
+				Usage: "`TIME` after which the test stops when --duration is unset, 0 to run without limit",
+				Value: 4 * time.Hour,
+			},
 			&cli.IntFlag{
 				Name:    "video-publishers",
 				Aliases: []string{"publishers"},
@@ -72,9 +79,18 @@ var LoadTestCommands = []*cli.Command{
 				Usage: "`NUMBER` of testers to start every second",
 				Value: 5,
 			},
+			&cli.FloatFlag{
+				Name:  "ramp-down",
+				Usage: "`NUMBER` of testers to disconnect every second after --duration, instead of all at once",
+			},
+			&cli.StringSliceFlag{
+				Name:      "publish-file",
+				Usage:     "Loop a VP8 .ivf or Opus .ogg `FILE` instead of synthetic media, can be repeated to spread files across publishers. Video files are published without simulcast",
+				TakesFile: true,
+			},
 			&cli.StringFlag{
 				Name:  "layout",
-				Usage: "`LAYOUT` to simulate, choose from \"speaker\", \"3x3\", \"4x4\", \"5x5\"",
+				Usage: "`LAYOUT` to simulate, \"speaker\" or a grid of columns x rows such as \"3x3\", \"5x5\" or \"8x6\" (up to 20x20)",
 				Value: "speaker",
 			},
 			&cli.BoolFlag{
@@ -85,6 +101,24 @@ var LoadTestCommands = []*cli.Command{
 				Name:  "simulate-speakers",
 				Usage: "Fire random speaker events to simulate speaker changes",
 			},
+			&cli.DurationFlag{
+				Name:  "connect-timeout",
+				Usage: "Give up on a tester's connection attempt after `TIME`, e.g. 10s (default: wait indefinitely)",
+			},
+			&cli.BoolFlag{
+				Name:  "force-relay",
+				Usage: "Only connect through the TURN servers configured for the project, to validate relay connectivity",
+			},
+			&cli.StringFlag{
+				Name:      "stats-out",
+				Usage:     "Periodically write per-tester and total stats to `FILE`, one JSON object per line",
+				TakesFile: true,
+			},
+			&cli.DurationFlag{
+				Name:  "stats-interval",
+				Usage: "How often to write to --stats-out, as a `TIME` such as 5s",
+				Value: 5 * time.Second,
+			},
 			&cli.BoolFlag{
 				Name:   "run-all",
 				Usage:  "Runs set list of load test cases",
@@ -105,23 +139,42 @@ func loadTest(ctx context.Context, cmd *cli.Command) error {
 	}
 	_ = raiseULimit()
 
+	layout, err := loadtester.LayoutFromString(cmd.String("layout"))
+	if err != nil {
+		return err
+	}
+
 	params := loadtester.Params{
-		VideoResolution:  cmd.String("video-resolution"),
-		VideoCodec:       cmd.String("video-codec"),
-		Duration:         cmd.Duration("duration"),
-		NumPerSecond:     cmd.Float("num-per-second"),
-		Simulcast:        !cmd.Bool("no-simulcast"),
-		SimulateSpeakers: cmd.Bool("simulate-speakers"),
+		VideoResolution:   cmd.String("video-resolution"),
+		VideoCodec:        cmd.String("video-codec"),
+		Duration:          cmd.Duration("duration"),
+		MaxDuration:       cmd.Duration("max-duration"),
+		NumPerSecond:      cmd.Float("num-per-second"),
+		RampDownPerSecond: cmd.Float("ramp-down"),
+		Simulcast:         !cmd.Bool("no-simulcast"),
+		SimulateSpeakers:  cmd.Bool("simulate-speakers"),
+		PublishFiles:      cmd.StringSlice("publish-file"),
+		StatsOut:          cmd.String("stats-out"),
+		StatsInterval:     cmd.Duration("stats-interval"),
 		TesterParams: loadtester.TesterParams{
-			URL:            pc.URL,
+			URL:            pc.APIURL(),
 			APIKey:         pc.APIKey,
 			APISecret:      pc.APISecret,
 			Room:           cmd.String("room"),
 			IdentityPrefix: cmd.String("identity-prefix"),
-			Layout:         loadtester.LayoutFromString(cmd.String("layout")),
+			Layout:         layout,
+			ForceRelay:     cmd.Bool("force-relay"),
+			ConnectTimeout: cmd.Duration("connect-timeout"),
 		},
 	}
 
+	if params.Duration > 0 && !cmd.IsSet("max-duration") {
+		// the default cap only guards against tests left running by mistake
+		params.MaxDuration = 0
+	} else if params.MaxDuration > 0 && params.Duration > params.MaxDuration {
+		return fmt.Errorf("--duration %s exceeds --max-duration %s", params.Duration, params.MaxDuration)
+	}
+
 	if cmd.Bool("run-all") {
 		// leave out room name and pub/sub counts
 		if params.Duration == 0 {
@@ -131,6 +184,10 @@ func loadTest(ctx context.Context, cmd *cli.Command) error {
 		return test.RunSuite(ctx)
 	}
 
+	if params.Duration == 0 && params.MaxDuration == 0 {
+		fmt.Fprintln(os.Stderr, "WARNING: neither --duration nor --max-duration is set, the load test will run until canceled")
+	}
+
 	params.VideoPublishers = int(cmd.Int("video-publishers"))
 	params.AudioPublishers = int(cmd.Int("audio-publishers"))
 	params.Subscribers = int(cmd.Int("subscribers"))
@@ -16,12 +16,15 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
 	"strings"
 	"syscall"
 
+	"github.com/twitchtv/twirp"
 	"github.com/urfave/cli/v3"
 
 	livekitcli "github.com/livekit/livekit-cli"
@@ -59,6 +62,7 @@ func main() {
 	app.Commands = append(app.Commands, AppCommands...)
 	app.Commands = append(app.Commands, CloudCommands...)
 	app.Commands = append(app.Commands, ProjectCommands...)
+	app.Commands = append(app.Commands, ConfigCommands...)
 	app.Commands = append(app.Commands, RoomCommands...)
 	app.Commands = append(app.Commands, TokenCommands...)
 	app.Commands = append(app.Commands, JoinCommands...)
@@ -68,6 +72,7 @@ func main() {
 	app.Commands = append(app.Commands, SIPCommands...)
 	app.Commands = append(app.Commands, ReplayCommands...)
 	app.Commands = append(app.Commands, LoadTestCommands...)
+	app.Commands = append(app.Commands, DoctorCommands...)
 
 	// Register cleanup hook for SIGINT, SIGTERM, SIGQUIT
 	ctx, stop := signal.NotifyContext(
@@ -85,9 +90,61 @@ func main() {
 
 	checkForLegacyName()
 
+	withConfigDefaults(app)
+
+	// help is rendered before any Before hook runs, so --include-hidden has to
+	// be applied ahead of parsing
+	if includeHidden(os.Args[1:]) {
+		showHiddenCommands(app)
+	}
+
 	if err := app.Run(ctx, os.Args); err != nil {
+		if printJSON || outputFormat == outputJSON {
+			printJSONError(err)
+			os.Exit(1)
+		}
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+type jsonError struct {
+	Message string `json:"message"`
+	Code    string `json:"code"`
+	Type    string `json:"type"`
+}
+
+// printJSONError writes err to stderr as {"error": {...}}, so that consumers of
+// --json output can parse failures as well as results.
+func printJSONError(err error) {
+	e := jsonError{
+		Message: err.Error(),
+		Code:    "unknown",
+		Type:    "cli_error",
+	}
+	var terr twirp.Error
+	switch {
+	case errors.As(err, &terr):
+		e.Message = terr.Msg()
+		e.Code = string(terr.Code())
+		e.Type = "api_error"
+	case errors.Is(err, context.DeadlineExceeded):
+		e.Code = "deadline_exceeded"
+		e.Type = "timeout"
+	case errors.Is(err, context.Canceled):
+		e.Code = "canceled"
+	}
+	_ = json.NewEncoder(os.Stderr).Encode(map[string]jsonError{"error": e})
+}
+
+// exitWithError reports err as main does, then exits with status 1 even when
+// the output isn't JSON, for commands whose exit status scripts rely on.
+func exitWithError(err error) error {
+	if printJSON || outputFormat == outputJSON {
+		printJSONError(err)
+	} else {
 		fmt.Fprintln(os.Stderr, err)
 	}
+	return cli.Exit("", 1)
 }
 
 func checkForLegacyName() {
@@ -103,6 +160,27 @@ func checkForLegacyName() {
 	}
 }
 
+func includeHidden(args []string) bool {
+	for _, arg := range args {
+		switch arg {
+		case "--":
+			return false
+		case "--include-hidden", "-include-hidden", "--include-hidden=true":
+			return true
+		}
+	}
+	return false
+}
+
+// showHiddenCommands un-hides every legacy command beneath cmd so that help
+// output and shell completion list them.
+func showHiddenCommands(cmd *cli.Command) {
+	for _, c := range cmd.Commands {
+		c.Hidden = false
+		showHiddenCommands(c)
+	}
+}
+
 func initLogger(ctx context.Context, cmd *cli.Command) (context.Context, error) {
 	logConfig := &logger.Config{
 		Level: "info",
@@ -16,9 +16,11 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
+	"os"
 	"regexp"
 
 	"github.com/charmbracelet/huh"
@@ -56,6 +58,10 @@ var (
 							Name:  "api-secret",
 							Usage: "Project `SECRET`",
 						},
+						&cli.StringFlag{
+							Name:  "region-url",
+							Usage: "Regional endpoint `URL` to always connect to instead of --url",
+						},
 						&cli.BoolFlag{
 							Name:  "default",
 							Usage: "Set this project as the default",
@@ -83,16 +89,137 @@ var (
 					ArgsUsage: "PROJECT_NAME",
 					Action:    setDefaultProject,
 				},
+				{
+					Name:      "export",
+					Usage:     "Export projects to a JSON file, to import on another machine",
+					UsageText: "lk project export [--include-secrets] [FILE]",
+					ArgsUsage: "[FILE]",
+					Action:    exportProjects,
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:  "include-secrets",
+							Usage: "Include API secrets in the export. Anyone with the file can access your projects",
+						},
+					},
+				},
+				{
+					Name:      "import",
+					Usage:     "Import projects from a file written by `lk project export`",
+					UsageText: "lk project import FILE",
+					ArgsUsage: "FILE",
+					Action:    importProjects,
+				},
 			},
 		},
 	}
 
 	cliConfig      *config.CLIConfig
 	defaultProject *config.ProjectConfig
-	nameRegex      = regexp.MustCompile(`^[a-zA-Z0-9_\-]+$`)
-	urlRegex       = regexp.MustCompile(`^(http|https|ws|wss)://[^\s/$.?#].[^\s]*$`)
+	// when set, takes precedence over --project, see forEachProject
+	projectOverride *config.ProjectConfig
+	nameRegex       = regexp.MustCompile(`^[a-zA-Z0-9_\-]+$`)
+	urlRegex        = regexp.MustCompile(`^(http|https|ws|wss)://[^\s/$.?#].[^\s]*$`)
 )
 
+// listing is the result of a list command: data is what structured output
+// prints, print writes it out for the current output format. A nil print means
+// the command has already written its output. With --exit-code, commands exit
+// with status 1 when the listing is empty.
+type listing struct {
+	data  any
+	print func() error
+	empty bool
+}
+
+type listFunc func(ctx context.Context, cmd *cli.Command) (*listing, error)
+
+// printListing turns a listFunc into an action printing its result.
+func printListing(list listFunc) cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		l, err := list(ctx, cmd)
+		if err != nil {
+			return err
+		}
+		if l.print != nil {
+			if err = l.print(); err != nil {
+				return err
+			}
+		}
+		return exitIfEmpty(cmd, l.empty)
+	}
+}
+
+func exitIfEmpty(cmd *cli.Command, empty bool) error {
+	if empty && cmd.Bool("exit-code") {
+		return cli.Exit("", 1)
+	}
+	return nil
+}
+
+// forEachProject runs a read-only list command once per --project when it's
+// given more than once, running before for each to point the clients at that
+// project. Commands wrapped this way must not set their own Before.
+// Table output is separated by a header per project, structured output is
+// combined into a single object keyed by project name.
+func forEachProject(before cli.BeforeFunc, list listFunc) cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		names := cmd.StringSlice("project")
+		if len(names) <= 1 {
+			if before != nil {
+				if _, err := before(ctx, cmd); err != nil {
+					return err
+				}
+			}
+			return printListing(list)(ctx, cmd)
+		}
+
+		projects := make([]*config.ProjectConfig, 0, len(names))
+		for _, name := range names {
+			pc, err := config.LoadProject(name)
+			if err != nil {
+				return fmt.Errorf("project %s: %w", name, err)
+			}
+			projects = append(projects, pc)
+		}
+		defer func() { projectOverride = nil }()
+
+		structured := structuredOutput(cmd)
+		results := make(map[string]any, len(projects))
+		empty := true
+		for i, pc := range projects {
+			projectOverride = pc
+			if before != nil {
+				if _, err := before(ctx, cmd); err != nil {
+					return fmt.Errorf("project %s: %w", pc.Name, err)
+				}
+			}
+			l, err := list(ctx, cmd)
+			if err != nil {
+				return fmt.Errorf("project %s: %w", pc.Name, err)
+			}
+			empty = empty && l.empty
+			if structured {
+				results[pc.Name] = l.data
+				continue
+			}
+			if l.print == nil {
+				continue
+			}
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Println("Project [" + util.Theme.Focused.Title.Render(pc.Name) + "]")
+			if err = l.print(); err != nil {
+				return fmt.Errorf("project %s: %w", pc.Name, err)
+			}
+		}
+		if structured {
+			printOutput(results)
+		}
+		return exitIfEmpty(cmd, empty)
+	}
+}
+
 func loadProjectConfig(ctx context.Context, cmd *cli.Command) (context.Context, error) {
 	conf, err := config.LoadOrCreate()
 	if err != nil {
@@ -164,6 +291,14 @@ func addProject(ctx context.Context, cmd *cli.Command) error {
 			Value(&p.URL))
 	}
 
+	// Regional URL
+	if p.RegionURL = cmd.String("region-url"); p.RegionURL != "" {
+		if err = validateURL(p.RegionURL); err != nil {
+			return err
+		}
+		fmt.Println("  Region URL:", p.RegionURL)
+	}
+
 	// API key
 	validateKey := func(val string) error {
 		if len(val) < 3 {
@@ -248,8 +383,8 @@ func listProjects(ctx context.Context, cmd *cli.Command) error {
 	headerStyle := baseStyle.Bold(true)
 	selectedStyle := util.Theme.Focused.Title.Padding(0, 1)
 
-	if cmd.Bool("json") {
-		util.PrintJSON(cliConfig.Projects)
+	if structuredOutput(cmd) {
+		printOutput(cliConfig.Projects)
 	} else {
 		table := util.CreateTable().
 			StyleFunc(func(row, col int) lipgloss.Style {
@@ -272,7 +407,9 @@ func listProjects(ctx context.Context, cmd *cli.Command) error {
 			}
 			table.Row(pName, p.URL, p.APIKey)
 		}
-		fmt.Println(table)
+		if err := printTable(table); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -309,3 +446,106 @@ func setDefaultProject(ctx context.Context, cmd *cli.Command) error {
 
 	return errors.New("project not found")
 }
+
+// projectExport is the portable format written by `lk project export`
+type projectExport struct {
+	DefaultProject string            `json:"default_project,omitempty"`
+	Projects       []exportedProject `json:"projects"`
+}
+
+type exportedProject struct {
+	Name      string `json:"name"`
+	URL       string `json:"url"`
+	APIKey    string `json:"api_key"`
+	APISecret string `json:"api_secret,omitempty"`
+	RegionURL string `json:"region_url,omitempty"`
+}
+
+func exportProjects(ctx context.Context, cmd *cli.Command) error {
+	if len(cliConfig.Projects) == 0 {
+		return errors.New("no projects configured")
+	}
+	includeSecrets := cmd.Bool("include-secrets")
+
+	export := projectExport{DefaultProject: cliConfig.DefaultProject}
+	for _, p := range cliConfig.Projects {
+		e := exportedProject{
+			Name:      p.Name,
+			URL:       p.URL,
+			APIKey:    p.APIKey,
+			RegionURL: p.RegionURL,
+		}
+		if includeSecrets {
+			e.APISecret = p.APISecret
+		}
+		export.Projects = append(export.Projects, e)
+	}
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if includeSecrets {
+		fmt.Fprintln(os.Stderr, "WARNING: this export contains API secrets. Anyone with access to it can control your projects, delete it once imported.")
+	}
+	file := cmd.Args().First()
+	if file == "" || file == "-" {
+		fmt.Println(string(data))
+		return nil
+	}
+	if err = os.WriteFile(file, append(data, '\n'), 0600); err != nil {
+		return err
+	}
+	fmt.Printf("Exported %d projects to %s\n", len(export.Projects), file)
+	return nil
+}
+
+func importProjects(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() == 0 {
+		_ = cli.ShowSubcommandHelp(cmd)
+		return errors.New("file is required")
+	}
+	data, err := os.ReadFile(cmd.Args().First())
+	if err != nil {
+		return err
+	}
+	var export projectExport
+	if err = json.Unmarshal(data, &export); err != nil {
+		return fmt.Errorf("could not parse %s: %w", cmd.Args().First(), err)
+	}
+
+	imported := 0
+	for _, e := range export.Projects {
+		switch {
+		case cliConfig.ProjectExists(e.Name):
+			fmt.Printf("Skipping [%s], a project with this name already exists\n", e.Name)
+			continue
+		case !nameRegex.MatchString(e.Name):
+			fmt.Printf("Skipping [%s], invalid project name\n", e.Name)
+			continue
+		case !urlRegex.MatchString(e.URL):
+			fmt.Printf("Skipping [%s], invalid URL %q\n", e.Name, e.URL)
+			continue
+		case e.APIKey == "" || e.APISecret == "":
+			fmt.Printf("Skipping [%s], the export has no API secret. Export with --include-secrets, or add it with `lk project add`\n", e.Name)
+			continue
+		}
+		cliConfig.Projects = append(cliConfig.Projects, config.ProjectConfig{
+			Name:      e.Name,
+			URL:       e.URL,
+			APIKey:    e.APIKey,
+			APISecret: e.APISecret,
+			RegionURL: e.RegionURL,
+		})
+		if cliConfig.DefaultProject == "" && e.Name == export.DefaultProject {
+			cliConfig.DefaultProject = e.Name
+		}
+		imported++
+	}
+
+	fmt.Printf("Imported %d of %d projects\n", imported, len(export.Projects))
+	if imported == 0 {
+		return nil
+	}
+	return cliConfig.PersistIfNeeded()
+}
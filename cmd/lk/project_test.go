@@ -0,0 +1,93 @@
+// Copyright 2024 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+)
+
+func TestForEachProjectExitCode(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.MkdirAll(filepath.Join(home, ".livekit"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	conf := `projects:
+  - name: p1
+    url: wss://p1.example.com
+    api_key: key1
+    api_secret: secret1
+  - name: p2
+    url: wss://p2.example.com
+    api_key: key2
+    api_secret: secret2
+`
+	if err := os.WriteFile(filepath.Join(home, ".livekit", "cli-config.yaml"), []byte(conf), 0600); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { outputFormat = outputTable }()
+
+	for _, tc := range []struct {
+		name     string
+		format   string
+		nonEmpty string
+		printed  int
+		exit     bool
+	}{
+		{"table, all empty", outputTable, "", 2, true},
+		{"table, one with results", outputTable, "p2", 2, false},
+		{"json, all empty", outputJSON, "", 0, true},
+		{"json, one with results", outputJSON, "p1", 0, false},
+	} {
+		outputFormat = tc.format
+		printed := 0
+		list := func(ctx context.Context, cmd *cli.Command) (*listing, error) {
+			name := projectOverride.Name
+			return &listing{
+				data:  name,
+				empty: name != tc.nonEmpty,
+				print: func() error {
+					printed++
+					return nil
+				},
+			}, nil
+		}
+		cmd := &cli.Command{
+			Name: "lk",
+			Flags: []cli.Flag{
+				&cli.StringSliceFlag{Name: "project"},
+				&cli.BoolFlag{Name: "exit-code"},
+			},
+			Action: forEachProject(nil, list),
+			// keep cli.Exit from terminating the test binary
+			ExitErrHandler: func(context.Context, *cli.Command, error) {},
+		}
+		err := cmd.Run(context.Background(), []string{"lk", "--project", "p1", "--project", "p2", "--exit-code"})
+
+		var exitErr cli.ExitCoder
+		if exited := errors.As(err, &exitErr) && exitErr.ExitCode() == 1; exited != tc.exit {
+			t.Errorf("%s: expected exit %v, got %v", tc.name, tc.exit, err)
+		}
+		if printed != tc.printed {
+			t.Errorf("%s: expected %d projects printed as text, got %d", tc.name, tc.printed, printed)
+		}
+	}
+}
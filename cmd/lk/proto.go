@@ -15,11 +15,15 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"reflect"
+	"sync"
 
 	"github.com/livekit/livekit-cli/pkg/util"
 	"github.com/urfave/cli/v3"
@@ -52,7 +56,14 @@ func ReadRequestArg[T any, P protoType[T]](cmd *cli.Command) (*T, error) {
 	if err != nil {
 		return nil, err
 	}
-	return ReadRequestFileOrLiteral[T, P](reqFile)
+	req, err := ReadRequestFileOrLiteral[T, P](reqFile)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.Bool("verbose") {
+		util.PrintJSONRedacted(req)
+	}
+	return req, nil
 }
 
 func ReadRequestArgOrFlag[T any, P protoType[T]](cmd *cli.Command) (*T, error) {
@@ -64,15 +75,7 @@ func ReadRequestArgOrFlag[T any, P protoType[T]](cmd *cli.Command) (*T, error) {
 }
 
 func ReadRequestFileOrLiteral[T any, P protoType[T]](pathOrLiteral string) (P, error) {
-	var reqBytes []byte
-	var err error
-
-	// This allows us to read JSON from either CLI arg or FS
-	if _, err = os.Stat(pathOrLiteral); err == nil {
-		reqBytes, err = os.ReadFile(pathOrLiteral)
-	} else {
-		reqBytes = []byte(pathOrLiteral)
-	}
+	reqBytes, err := readRequestBytes(pathOrLiteral)
 	if err != nil {
 		return nil, err
 	}
@@ -85,6 +88,30 @@ func ReadRequestFileOrLiteral[T any, P protoType[T]](pathOrLiteral string) (P, e
 	return req, nil
 }
 
+// stdin can only be consumed once, but a request may be read more than once,
+// e.g. to infer its type before parsing it
+var readStdinRequest = sync.OnceValues(func() ([]byte, error) {
+	return io.ReadAll(os.Stdin)
+})
+
+func readRequestBytes(pathOrLiteral string) ([]byte, error) {
+	if pathOrLiteral == "-" {
+		reqBytes, err := readStdinRequest()
+		if err != nil {
+			return nil, fmt.Errorf("could not read request from stdin: %w", err)
+		}
+		if len(bytes.TrimSpace(reqBytes)) == 0 {
+			return nil, errors.New("no request received on stdin")
+		}
+		return reqBytes, nil
+	}
+	// This allows us to read JSON from either CLI arg or FS
+	if _, err := os.Stat(pathOrLiteral); err == nil {
+		return os.ReadFile(pathOrLiteral)
+	}
+	return []byte(pathOrLiteral), nil
+}
+
 func RequestFlag[T any, P protoType[T]]() *cli.StringFlag {
 	return &cli.StringFlag{
 		Name:     flagRequest,
@@ -109,7 +136,7 @@ func createAndPrint[T any, P protoTypeValidator[T], R any](
 		return fmt.Errorf("could not read request: %w", err)
 	}
 	if cmd.Bool("verbose") {
-		util.PrintJSON(req)
+		util.PrintJSONRedacted(req)
 	}
 	if err = req.Validate(); err != nil {
 		return err
@@ -133,7 +160,7 @@ func createAndPrintLegacy[T any, P protoType[T], R any](
 		return err
 	}
 	if cmd.Bool("verbose") {
-		util.PrintJSON(req)
+		util.PrintJSONRedacted(req)
 	}
 	info, err := create(ctx, req)
 	if err != nil {
@@ -174,7 +201,7 @@ func forEachID(ctx context.Context, cmd *cli.Command, fnc func(ctx context.Conte
 	return nil
 }
 
-func listAndPrint[
+func listItems[
 	ReqT any, Req protoType[ReqT],
 	T any, _ protoType[T],
 	Resp interface {
@@ -186,29 +213,35 @@ func listAndPrint[
 	cmd *cli.Command,
 	getList func(ctx context.Context, req Req) (Resp, error), req Req,
 	header []string, tableRow func(item *T) []string,
-) error {
+) (*listing, error) {
 	res, err := getList(ctx, req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if cmd.Bool("json") {
-		util.PrintJSON(res)
-	} else {
-		table := util.CreateTable().
-			Headers(header...)
-		for _, item := range res.GetItems() {
-			if item == nil {
-				continue
+	// SIP trunks carry their auth passwords, mask them as the table does
+	return &listing{data: json.RawMessage(util.RedactJSON(res)), print: func() error {
+		if cmd.IsSet("template") {
+			return util.PrintTemplate(cmd.String("template"), res.GetItems()...)
+		} else if structuredOutput(cmd) {
+			printOutputRedacted(res)
+		} else {
+			table := util.CreateTable().
+				Headers(header...)
+			for _, item := range res.GetItems() {
+				if item == nil {
+					continue
+				}
+				row := tableRow(item)
+				if len(row) == 0 {
+					continue
+				}
+				table.Row(row...)
 			}
-			row := tableRow(item)
-			if len(row) == 0 {
-				continue
+			if err := printTable(table); err != nil {
+				return err
 			}
-			table.Row(row...)
 		}
-		fmt.Println(table)
-	}
-
-	return nil
+		return nil
+	}}, nil
 }
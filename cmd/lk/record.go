@@ -0,0 +1,216 @@
+// Copyright 2024 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pion/webrtc/v4"
+	"github.com/pion/webrtc/v4/pkg/media"
+	"github.com/pion/webrtc/v4/pkg/media/h264writer"
+	"github.com/pion/webrtc/v4/pkg/media/ivfwriter"
+	"github.com/pion/webrtc/v4/pkg/media/oggwriter"
+
+	"github.com/livekit/protocol/logger"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+const (
+	recordingManifest = "manifest.json"
+	// how long to wait for tracks to be flushed to disk after leaving
+	recordingCloseTimeout = 5 * time.Second
+)
+
+// trackRecorder writes every subscribed track to its own file. By default the
+// files are laid out as DIR/<room>/<identity>/<source>-<sid>.<ext>, or as
+// DIR/<room>-<identity>-<source>-<sid>.<ext> when flat.
+type trackRecorder struct {
+	dir  string
+	room string
+	flat bool
+
+	lock       sync.Mutex
+	wg         sync.WaitGroup
+	recordings []*trackRecording
+}
+
+type trackRecording struct {
+	Participant string    `json:"participant"`
+	TrackSID    string    `json:"track_sid"`
+	Source      string    `json:"source"`
+	Codec       string    `json:"codec"`
+	Path        string    `json:"path"`
+	StartedAt   time.Time `json:"started_at"`
+	StoppedAt   time.Time `json:"stopped_at"`
+	Bytes       int64     `json:"bytes"`
+	Error       string    `json:"error,omitempty"`
+}
+
+func newTrackRecorder(dir, room string, flat bool) *trackRecorder {
+	return &trackRecorder{
+		dir:  dir,
+		room: room,
+		flat: flat,
+	}
+}
+
+func (r *trackRecorder) path(identity, source, sid, ext string) string {
+	identity = sanitizePathElement(identity)
+	room := sanitizePathElement(r.room)
+	if r.flat {
+		return filepath.Join(r.dir, fmt.Sprintf("%s-%s-%s-%s%s", room, identity, source, sid, ext))
+	}
+	return filepath.Join(r.dir, room, identity, fmt.Sprintf("%s-%s%s", source, sid, ext))
+}
+
+// Record starts writing track to disk until it ends.
+func (r *trackRecorder) Record(track *webrtc.TrackRemote, pub *lksdk.RemoteTrackPublication, participant *lksdk.RemoteParticipant) {
+	mimeType := track.Codec().MimeType
+	source := strings.ToLower(pub.Source().String())
+
+	var ext string
+	switch {
+	case strings.EqualFold(mimeType, webrtc.MimeTypeOpus):
+		ext = ".ogg"
+	case strings.EqualFold(mimeType, webrtc.MimeTypeVP8), strings.EqualFold(mimeType, webrtc.MimeTypeAV1):
+		ext = ".ivf"
+	case strings.EqualFold(mimeType, webrtc.MimeTypeH264):
+		ext = ".h264"
+	default:
+		logger.Warnw("cannot record track, unsupported codec", nil, "trackID", pub.SID(), "codec", mimeType)
+		return
+	}
+
+	rec := &trackRecording{
+		Participant: participant.Identity(),
+		TrackSID:    pub.SID(),
+		Source:      source,
+		Codec:       mimeType,
+		Path:        r.path(participant.Identity(), source, pub.SID(), ext),
+		StartedAt:   time.Now(),
+	}
+	r.lock.Lock()
+	r.recordings = append(r.recordings, rec)
+	r.lock.Unlock()
+
+	writer, err := r.createWriter(rec.Path, ext, mimeType, track)
+	if err != nil {
+		logger.Warnw("cannot record track", err, "trackID", pub.SID())
+		r.finish(rec, err)
+		return
+	}
+	if track.Kind() == webrtc.RTPCodecTypeVideo {
+		// start from a keyframe so the file is playable
+		participant.WritePLI(track.SSRC())
+	}
+	logger.Infow("recording track", "trackID", pub.SID(), "path", rec.Path)
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		for {
+			pkt, _, err := track.ReadRTP()
+			if err != nil {
+				break
+			}
+			if err = writer.WriteRTP(pkt); err != nil {
+				logger.Warnw("failed to write packet", err, "trackID", pub.SID())
+			}
+		}
+		r.finish(rec, writer.Close())
+	}()
+}
+
+func (r *trackRecorder) createWriter(path, ext, mimeType string, track *webrtc.TrackRemote) (media.Writer, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	switch ext {
+	case ".ogg":
+		return oggwriter.New(path, track.Codec().ClockRate, track.Codec().Channels)
+	case ".ivf":
+		return ivfwriter.New(path, ivfwriter.WithCodec(mimeType))
+	default:
+		return h264writer.New(path)
+	}
+}
+
+func (r *trackRecorder) finish(rec *trackRecording, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	rec.StoppedAt = time.Now()
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	if info, statErr := os.Stat(rec.Path); statErr == nil {
+		rec.Bytes = info.Size()
+	}
+}
+
+// Close waits for open recordings to end and writes a manifest describing
+// every recorded track.
+func (r *trackRecorder) Close() error {
+	flushed := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(flushed)
+	}()
+	select {
+	case <-flushed:
+	case <-time.After(recordingCloseTimeout):
+		logger.Warnw("timed out waiting for recordings to finish", nil)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if len(r.recordings) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(r.dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(map[string]any{
+		"room":   r.room,
+		"tracks": r.recordings,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	manifestPath := filepath.Join(r.dir, recordingManifest)
+	if err = os.WriteFile(manifestPath, data, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("Recorded %d track(s), manifest written to %s\n", len(r.recordings), manifestPath)
+	return nil
+}
+
+func sanitizePathElement(s string) string {
+	if s == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		return r
+	}, s)
+}
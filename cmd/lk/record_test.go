@@ -0,0 +1,31 @@
+// Copyright 2024 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrackRecorderPath(t *testing.T) {
+	r := newTrackRecorder("out", "my-room", false)
+	assert.Equal(t, filepath.Join("out", "my-room", "alice", "camera-TR_1.ivf"), r.path("alice", "camera", "TR_1", ".ivf"))
+	assert.Equal(t, filepath.Join("out", "my-room", "sip_+1555", "microphone-TR_2.ogg"), r.path("sip/+1555", "microphone", "TR_2", ".ogg"))
+
+	r = newTrackRecorder("out", "my-room", true)
+	assert.Equal(t, filepath.Join("out", "my-room-alice-camera-TR_1.ivf"), r.path("alice", "camera", "TR_1", ".ivf"))
+}
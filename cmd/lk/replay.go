@@ -119,7 +119,7 @@ func createReplayClient(ctx context.Context, cmd *cli.Command) (context.Context,
 		return nil, err
 	}
 
-	url := lksdk.ToHttpURL(pc.URL)
+	url := lksdk.ToHttpURL(pc.APIURL())
 	client := replay.NewReplayProtobufClient(url, &http.Client{}, withDefaultClientOpts(pc)...)
 	replayClient = &replayServiceClient{
 		Replay:    client,
@@ -141,14 +141,16 @@ func listReplays(ctx context.Context, cmd *cli.Command) error {
 		return err
 	}
 
-	if cmd.Bool("json") {
-		util.PrintJSON(res.Replays)
+	if structuredOutput(cmd) {
+		printOutput(res.Replays)
 	} else {
 		table := util.CreateTable().Headers("ReplayID")
 		for _, info := range res.Replays {
 			table.Row(info.ReplayId)
 		}
-		fmt.Println(table)
+		if err := printTable(table); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -15,13 +15,20 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/pion/webrtc/v4"
 	"github.com/urfave/cli/v3"
@@ -53,22 +60,22 @@ var (
 						},
 						&cli.StringFlag{
 							Name:      "room-egress-file",
-							Usage:     "RoomCompositeRequest `JSON` file (see examples/room-composite-file.json)",
+							Usage:     "RoomCompositeRequest `JSON` file, or literal JSON (see examples/room-composite-file.json)",
 							TakesFile: true,
 						},
 						&cli.StringFlag{
 							Name:      "participant-egress-file",
-							Usage:     "ParticipantEgress `JSON` file (see examples/auto-participant-egress.json)",
+							Usage:     "ParticipantEgress `JSON` file, or literal JSON (see examples/auto-participant-egress.json)",
 							TakesFile: true,
 						},
 						&cli.StringFlag{
 							Name:      "track-egress-file",
-							Usage:     "AutoTrackEgress `JSON` file (see examples/auto-track-egress.json)",
+							Usage:     "AutoTrackEgress `JSON` file, or literal JSON (see examples/auto-track-egress.json)",
 							TakesFile: true,
 						},
 						&cli.StringFlag{
 							Name:      "agents-file",
-							Usage:     "Agents configuration `JSON` file",
+							Usage:     "Agents configuration `JSON` file, or literal JSON",
 							TakesFile: true,
 						},
 						&cli.StringFlag{
@@ -95,6 +102,23 @@ var (
 							Name:  "departure-timeout",
 							Usage: "Number of `SECS` to keep the room open after the last participant leaves",
 						},
+						&cli.UintFlag{
+							Name:  "max-participants",
+							Usage: "Maximum `NUMBER` of participants allowed in the room",
+						},
+						&cli.BoolFlag{
+							Name:  "wait-ready",
+							Usage: "After creating the room, wait until an agent has joined it, the one given with --agent-name if set",
+						},
+						&cli.StringFlag{
+							Name:  "agent-name",
+							Usage: "`NAME` of the agent to dispatch to the room, used with --wait-ready",
+						},
+						&cli.DurationFlag{
+							Name:  "timeout",
+							Usage: "How long to wait for the agent to join, used with --wait-ready",
+							Value: 30 * time.Second,
+						},
 						&cli.BoolFlag{
 							Name:   "replay-enabled",
 							Usage:  "experimental (not yet available)",
@@ -105,10 +129,17 @@ var (
 				{
 					Name:      "list",
 					Usage:     "List or search for active rooms by name",
-					Before:    createRoomClient,
-					Action:    listRooms,
+					Action:    forEachProject(createRoomClient, listRooms),
 					ArgsUsage: "[ROOM_NAME ...]",
-					Flags:     []cli.Flag{jsonFlag},
+					Flags: []cli.Flag{
+						jsonFlag,
+						templateFlag[livekit.Room](),
+						&cli.BoolFlag{
+							Name:    "exit-code",
+							Aliases: []string{"e"},
+							Usage:   "Exit with status 1 when no rooms match, e.g. for health checks",
+						},
+					},
 				},
 				{
 					Name:   "update",
@@ -145,12 +176,40 @@ var (
 							Name:  "publish-demo",
 							Usage: "Publish demo video as a loop",
 						},
+						&cli.DurationFlag{
+							Name:  "connect-timeout",
+							Usage: "Give up if connecting to the room takes longer than `TIME`, e.g. 10s (default: wait indefinitely)",
+						},
+						&cli.BoolFlag{
+							Name:  "force-relay",
+							Usage: "Only connect through the TURN servers configured for the project, to validate relay connectivity",
+						},
+						&cli.BoolFlag{
+							Name:  "record-tracks",
+							Usage: "Record every subscribed track to a file, along with a manifest.json describing them",
+						},
+						&cli.StringFlag{
+							Name:      "output-dir",
+							Usage:     "`DIR` to write recordings to, as DIR/<room>/<identity>/<source>-<sid>.<ext>",
+							Value:     "recordings",
+							TakesFile: true,
+						},
+						&cli.BoolFlag{
+							Name:  "flat",
+							Usage: "Write recordings directly into --output-dir as <room>-<identity>-<source>-<sid>.<ext>",
+						},
+						&cli.BoolFlag{
+							Name:  "publish-mic",
+							Usage: "Capture the default microphone with ffmpeg and publish it as an Opus track (Linux and macOS)",
+						},
 						&cli.StringSliceFlag{
 							Name:      "publish",
 							TakesFile: true,
 							Usage: "`FILES` to publish as tracks to room (supports .h264, .ivf, .ogg). " +
 								"Can be used multiple times to publish multiple files. " +
-								"Can publish from Unix or TCP socket using the format '<codec>://<socket_name>' or '<codec>://<host:address>' respectively. Valid codecs are \"h264\", \"vp8\", \"opus\"",
+								"Can publish from Unix or TCP socket using the format '<codec>://<socket_name>' or '<codec>://<host:address>' respectively. Valid codecs are \"h264\", \"vp8\", \"opus\" " +
+								"Prefix with 'source=<source>:' to set the track source, e.g. 'source=screen_share:video.h264'. " +
+								"Quoted glob patterns such as './clips/*.ivf' publish each matching file as its own track",
 						},
 						&cli.StringFlag{
 							Name:  "publish-data",
@@ -168,18 +227,69 @@ var (
 							Name:  "exit-after-publish",
 							Usage: "When publishing, exit after file or stream is complete",
 						},
+						&cli.StringFlag{
+							Name:  "participant-metadata",
+							Usage: "`METADATA` of the joining participant",
+						},
+						&cli.StringSliceFlag{
+							Name:  "attribute",
+							Usage: "Set an attribute of the joining participant as `KEY=VALUE`, can be used multiple times",
+						},
+						&cli.StringFlag{
+							Name:  "record",
+							Usage: "Record what this participant publishes with a participant egress, writing to file `OUTPUT` or streaming to an RTMP or SRT url. The egress is stopped on exit",
+						},
+						&cli.StringFlag{
+							Name:  "exit-after-event",
+							Usage: "Exit once --count `EVENT`s have been observed, one of " + strings.Join(joinExitEvents, ", ") + ". Exits with an error if interrupted or disconnected first",
+						},
+						&cli.IntFlag{
+							Name:  "count",
+							Usage: "`NUMBER` of events to wait for with --exit-after-event",
+							Value: 1,
+						},
 					},
 				},
 				{
-					Name:   "participants",
-					Usage:  "Manage room participants",
-					Before: createRoomClient,
+					Name:  "participants",
+					Usage: "Manage room participants",
 					Commands: []*cli.Command{
 						{
 							Name:      "list",
 							Usage:     "List or search for active rooms by name",
-							Action:    listParticipants,
+							Action:    forEachProject(createRoomClient, listParticipants),
 							ArgsUsage: "ROOM_NAME",
+							Flags: []cli.Flag{
+								jsonFlag,
+								templateFlag[livekit.ParticipantInfo](),
+							},
+						},
+						{
+							Name:      "count",
+							Usage:     "Print the number of participants in a room",
+							ArgsUsage: "ROOM_NAME",
+							Before:    createRoomClient,
+							Action:    countParticipants,
+							Flags: []cli.Flag{
+								optional(roomFlag),
+								&cli.BoolFlag{
+									Name:  "publishers-only",
+									Usage: "Only count participants that have published tracks",
+								},
+								&cli.IntFlag{
+									Name:  "min",
+									Usage: "With --exit-code, exit with status 1 when fewer than `COUNT` participants are present",
+								},
+								&cli.IntFlag{
+									Name:  "max",
+									Usage: "With --exit-code, exit with status 1 when more than `COUNT` participants are present",
+								},
+								&cli.BoolFlag{
+									Name:    "exit-code",
+									Aliases: []string{"e"},
+									Usage:   "Exit with status 1 when the count is outside --min and --max, e.g. for capacity alerts",
+								},
+							},
 						},
 						{
 							Name:      "get",
@@ -189,6 +299,7 @@ var (
 							Action:    getParticipant,
 							Flags: []cli.Flag{
 								roomFlag,
+								templateFlag[livekit.ParticipantInfo](),
 							},
 						},
 						{
@@ -213,19 +324,41 @@ var (
 									Name:  "metadata",
 									Usage: "JSON describing participant metadata (existing values for unset fields)",
 								},
+								&cli.BoolFlag{
+									Name:  "metadata-json",
+									Usage: "Check that --metadata is valid JSON before sending it",
+								},
 								&cli.StringFlag{
 									Name:  "permissions",
 									Usage: "JSON describing participant permissions (existing values for unset fields)",
 								},
 							},
 						},
+						{
+							Name:      "mute-all",
+							Usage:     "Mute the audio tracks of every participant in a room",
+							ArgsUsage: "ROOM_NAME",
+							Before:    createRoomClient,
+							Action:    muteAllParticipants,
+							Flags: []cli.Flag{
+								optional(roomFlag),
+								&cli.BoolFlag{
+									Name:  "video",
+									Usage: "Mute video tracks as well",
+								},
+								&cli.StringSliceFlag{
+									Name:  "except",
+									Usage: "`IDENTITY` of a participant to leave unmuted, e.g. a moderator. Can be used multiple times",
+								},
+							},
+						},
 					},
 				},
 				{
 					Name:      "mute-track",
-					Usage:     "Mute or unmute a track",
-					UsageText: "lk room mute-track OPTIONS TRACK_SID",
-					ArgsUsage: "TRACK_SID",
+					Usage:     "Mute or unmute a track, or all of a participant's tracks when TRACK_SID is omitted",
+					UsageText: "lk room mute-track OPTIONS [TRACK_SID]",
+					ArgsUsage: "[TRACK_SID]",
 					Before:    createRoomClient,
 					Action:    muteTrack,
 					MutuallyExclusiveFlags: []cli.MutuallyExclusiveFlags{{
@@ -252,6 +385,11 @@ var (
 							Name:   "track",
 							Usage:  "Track `SID` to mute",
 						},
+						sourceFlag,
+						&cli.BoolFlag{
+							Name:  "all-tracks",
+							Usage: "Mute or unmute every track published by the participant, instead of TRACK_SID",
+						},
 					},
 				},
 				{
@@ -273,6 +411,11 @@ var (
 							Name:  "subscribe",
 							Usage: "Set to true to subscribe, otherwise it'll unsubscribe",
 						},
+						&cli.StringFlag{
+							Name:  "publisher",
+							Usage: "`IDENTITY` of the participant publishing the tracks, used with --source",
+						},
+						sourceFlag,
 					},
 				},
 				{
@@ -297,6 +440,28 @@ var (
 							Name:  "identity",
 							Usage: "One or more participant identities to send the message to. When empty, broadcasts to the entire room",
 						},
+						&cli.StringSliceFlag{
+							Name:  "sid",
+							Usage: "One or more participant `SID`s to send the message to. Combined with --identity, the message goes to every matching participant",
+						},
+						&cli.StringFlag{
+							Name:      "from-jsonl",
+							Usage:     "Send each line of JSON Lines `FILE` as a message, formatted as {\"topic\", \"identities\", \"payload_base64\"}",
+							TakesFile: true,
+						},
+						&cli.FloatFlag{
+							Name:  "rate",
+							Usage: "Maximum number of messages per second to send with --from-jsonl (0 for no limit)",
+						},
+						&cli.StringFlag{
+							Name:      "data-file",
+							Usage:     "Send the contents of `FILE` as the payload instead of DATA, e.g. for large or binary messages",
+							TakesFile: true,
+						},
+						&cli.BoolFlag{
+							Name:  "lossy",
+							Usage: "Send as a lossy (unreliable) data packet instead of a reliable one",
+						},
 					},
 				},
 			},
@@ -365,7 +530,7 @@ var (
 			Hidden: true, // deprecated: use `room list``
 			Name:   "list-rooms",
 			Before: createRoomClient,
-			Action: listRooms,
+			Action: printListing(listRooms),
 		},
 		{
 			Hidden: true, // deprecated: use `room list`
@@ -519,16 +684,30 @@ var (
 		},
 	}
 
+	sourceFlag = &cli.StringSliceFlag{
+		Name:  "source",
+		Usage: "Resolve track SIDs by `SOURCE` (camera, microphone, screen_share, screen_share_audio) instead of passing TRACK_SID",
+	}
+
+	// events that room join can wait for with --exit-after-event
+	joinExitEvents = []string{joinEventTrackSubscribed, joinEventData, joinEventParticipantConnected}
+
 	roomClient *lksdk.RoomServiceClient
 )
 
+const (
+	joinEventTrackSubscribed      = "track-subscribed"
+	joinEventData                 = "data"
+	joinEventParticipantConnected = "participant-connected"
+)
+
 func createRoomClient(ctx context.Context, cmd *cli.Command) (context.Context, error) {
 	pc, err := loadProjectDetails(cmd)
 	if err != nil {
 		return nil, err
 	}
 
-	roomClient = lksdk.NewRoomServiceClient(pc.URL, pc.APIKey, pc.APISecret, withDefaultClientOpts(pc)...)
+	roomClient = lksdk.NewRoomServiceClient(pc.APIURL(), pc.APIKey, pc.APISecret, withDefaultClientOpts(pc)...)
 	return nil, nil
 }
 
@@ -544,7 +723,7 @@ func createRoom(ctx context.Context, cmd *cli.Command) error {
 
 	if roomEgressFile := cmd.String("room-egress-file"); roomEgressFile != "" {
 		roomEgress := &livekit.RoomCompositeEgressRequest{}
-		b, err := os.ReadFile(roomEgressFile)
+		b, err := readJSONFileOrLiteral(roomEgressFile)
 		if err != nil {
 			return err
 		}
@@ -556,7 +735,7 @@ func createRoom(ctx context.Context, cmd *cli.Command) error {
 
 	if participantEgressFile := cmd.String("participant-egress-file"); participantEgressFile != "" {
 		participantEgress := &livekit.AutoParticipantEgress{}
-		b, err := os.ReadFile(participantEgressFile)
+		b, err := readJSONFileOrLiteral(participantEgressFile)
 		if err != nil {
 			return err
 		}
@@ -571,7 +750,7 @@ func createRoom(ctx context.Context, cmd *cli.Command) error {
 
 	if trackEgressFile := cmd.String("track-egress-file"); trackEgressFile != "" {
 		trackEgress := &livekit.AutoTrackEgress{}
-		b, err := os.ReadFile(trackEgressFile)
+		b, err := readJSONFileOrLiteral(trackEgressFile)
 		if err != nil {
 			return err
 		}
@@ -586,7 +765,7 @@ func createRoom(ctx context.Context, cmd *cli.Command) error {
 
 	if agentsFile := cmd.String("agents-file"); agentsFile != "" {
 		agent := &livekit.RoomAgent{}
-		b, err := os.ReadFile(agentsFile)
+		b, err := readJSONFileOrLiteral(agentsFile)
 		if err != nil {
 			return err
 		}
@@ -625,21 +804,122 @@ func createRoom(ctx context.Context, cmd *cli.Command) error {
 		req.DepartureTimeout = uint32(departureTimeout)
 	}
 
+	if maxParticipants := cmd.Uint("max-participants"); maxParticipants != 0 {
+		fmt.Printf("setting max participants: %d\n", maxParticipants)
+		req.MaxParticipants = uint32(maxParticipants)
+	}
+
 	if replayEnabled := cmd.Bool("replay-enabled"); replayEnabled {
 		fmt.Printf("setting replay enabled: %t\n", replayEnabled)
 		req.ReplayEnabled = replayEnabled
 	}
 
+	if agentName := cmd.String("agent-name"); agentName != "" {
+		if !slices.ContainsFunc(req.Agents, func(a *livekit.RoomAgentDispatch) bool { return a.AgentName == agentName }) {
+			fmt.Printf("dispatching agent: %s\n", agentName)
+			req.Agents = append(req.Agents, &livekit.RoomAgentDispatch{AgentName: agentName})
+		}
+	}
+
 	room, err := roomClient.CreateRoom(ctx, req)
 	if err != nil {
 		return err
 	}
 
 	util.PrintJSON(room)
+
+	if cmd.Bool("wait-ready") {
+		if cmd.String("agent-name") != "" {
+			// the agent's participant is found through its dispatch
+			if _, err = createDispatchClient(ctx, cmd); err != nil {
+				return err
+			}
+		}
+		timeout := cmd.Duration("timeout")
+		fmt.Printf("waiting up to %s for an agent to join...\n", timeout)
+		agent, err := waitForAgent(ctx, room.Name, cmd.String("agent-name"), timeout)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("agent %s joined room %s\n", agent.Identity, room.Name)
+	}
 	return nil
 }
 
-func listRooms(ctx context.Context, cmd *cli.Command) error {
+// readJSONFileOrLiteral treats value as inline JSON when it starts with '{',
+// and as the path of a JSON file otherwise.
+func readJSONFileOrLiteral(value string) ([]byte, error) {
+	if strings.HasPrefix(strings.TrimSpace(value), "{") {
+		return []byte(value), nil
+	}
+	return os.ReadFile(value)
+}
+
+// waitForAgent polls the room until an agent participant has joined. When
+// agentName is set, only the participant of a job dispatched for that agent
+// counts, otherwise any participant of kind agent does.
+func waitForAgent(ctx context.Context, roomName, agentName string, timeout time.Duration) (*livekit.ParticipantInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		p, err := findAgent(ctx, roomName, agentName)
+		if err != nil && ctx.Err() == nil {
+			return nil, err
+		}
+		if p != nil {
+			return p, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, fmt.Errorf("no agent joined room %s within %s", roomName, timeout)
+			}
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func findAgent(ctx context.Context, roomName, agentName string) (*livekit.ParticipantInfo, error) {
+	var identities map[string]bool
+	if agentName != "" {
+		res, err := dispatchClient.ListDispatch(ctx, &livekit.ListAgentDispatchRequest{Room: roomName})
+		if err != nil {
+			return nil, err
+		}
+		identities = make(map[string]bool)
+		for _, d := range res.AgentDispatches {
+			if d.AgentName != agentName {
+				continue
+			}
+			for _, job := range d.GetState().GetJobs() {
+				if identity := job.GetState().GetParticipantIdentity(); identity != "" {
+					identities[identity] = true
+				}
+			}
+		}
+		if len(identities) == 0 {
+			return nil, nil
+		}
+	}
+
+	res, err := roomClient.ListParticipants(ctx, &livekit.ListParticipantsRequest{Room: roomName})
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range res.Participants {
+		if p.Kind == livekit.ParticipantInfo_AGENT && (agentName == "" || identities[p.Identity]) {
+			return p, nil
+		}
+	}
+	return nil, nil
+}
+
+func listRooms(ctx context.Context, cmd *cli.Command) (*listing, error) {
 	names, _ := extractArgs(cmd)
 	if cmd.Bool("verbose") && len(names) > 0 {
 		fmt.Printf(
@@ -655,25 +935,30 @@ func listRooms(ctx context.Context, cmd *cli.Command) error {
 
 	res, err := roomClient.ListRooms(ctx, &req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if cmd.Bool("json") {
-		util.PrintJSON(res)
-	} else {
-		table := util.CreateTable().Headers("RoomID", "Name", "Participants", "Publishers")
-		for _, rm := range res.Rooms {
-			table.Row(
-				rm.Sid,
-				rm.Name,
-				fmt.Sprintf("%d", rm.NumParticipants),
-				fmt.Sprintf("%d", rm.NumPublishers),
-			)
+	return &listing{data: res, empty: len(res.Rooms) == 0, print: func() error {
+		if cmd.IsSet("template") {
+			if err := util.PrintTemplate(cmd.String("template"), res.Rooms...); err != nil {
+				return err
+			}
+		} else if structuredOutput(cmd) {
+			printOutput(res)
+		} else {
+			table := util.CreateTable().Headers("RoomID", "Name", "Participants", "Publishers")
+			for _, rm := range res.Rooms {
+				table.Row(
+					rm.Sid,
+					rm.Name,
+					fmt.Sprintf("%d", rm.NumParticipants),
+					fmt.Sprintf("%d", rm.NumPublishers),
+				)
+			}
+			return printTable(table)
 		}
-		fmt.Println(table)
-	}
-
-	return nil
+		return nil
+	}}, nil
 }
 
 func _deprecatedListRoom(ctx context.Context, cmd *cli.Command) error {
@@ -751,12 +1036,65 @@ func joinRoom(ctx context.Context, cmd *cli.Command) error {
 	}
 
 	participantIdentity := cmd.String("identity")
+	attributes, err := parseKeyValuePairs(cmd.StringSlice("attribute"))
+	if err != nil {
+		return err
+	}
+
+	exitEvent := cmd.String("exit-after-event")
+	exitCount := cmd.Int("count")
+	if exitEvent != "" && !slices.Contains(joinExitEvents, exitEvent) {
+		return fmt.Errorf("unknown event %q, expected one of %s", exitEvent, strings.Join(joinExitEvents, ", "))
+	}
+	if exitCount < 1 {
+		return errors.New("--count must be at least 1")
+	}
+	recordOutput := cmd.String("record")
+	if recordOutput != "" {
+		if !cmd.IsSet("publish") && !cmd.Bool("publish-demo") && !cmd.Bool("publish-mic") {
+			return errors.New("--record requires --publish, --publish-demo or --publish-mic")
+		}
+		if strings.Contains(recordOutput, "://") {
+			if err = validateStreamURL(recordOutput); err != nil {
+				return err
+			}
+		}
+		if _, err = createEgressClient(ctx, cmd); err != nil {
+			return err
+		}
+	}
+
+	var recorder *trackRecorder
+	if cmd.Bool("record-tracks") {
+		recorder = newTrackRecorder(cmd.String("output-dir"), roomName, cmd.Bool("flat"))
+		// deferred ahead of room.Disconnect, so that tracks have ended by the
+		// time the manifest is written
+		defer func() {
+			if err := recorder.Close(); err != nil {
+				logger.Errorw("failed to write recording manifest", err)
+			}
+		}()
+	}
 
 	done := make(chan os.Signal, 1)
+	// exit may be reached from several callbacks, so done must only be closed once
+	exit := sync.OnceFunc(func() {
+		signal.Stop(done)
+		close(done)
+	})
+	var observed atomic.Int64
+	observe := func(event string) {
+		if event == exitEvent && observed.Add(1) == exitCount {
+			logger.Infow("observed expected events, exiting", "event", event, "count", exitCount)
+			exit()
+		}
+	}
+
 	roomCB := &lksdk.RoomCallback{
 		ParticipantCallback: lksdk.ParticipantCallback{
 			OnDataPacket: func(p lksdk.DataPacket, params lksdk.DataReceiveParams) {
 				identity := params.SenderIdentity
+				defer observe(joinEventData)
 				switch p := p.(type) {
 				case *lksdk.UserDataPacket:
 					logger.Infow("received data", "data", p.Payload, "participant", identity)
@@ -776,6 +1114,10 @@ func joinRoom(ctx context.Context, cmd *cli.Command) error {
 					"source", pub.Source(),
 					"participant", participant.Identity(),
 				)
+				if recorder != nil {
+					recorder.Record(track, pub, participant)
+				}
+				observe(joinEventTrackSubscribed)
 			},
 			OnTrackUnsubscribed: func(track *webrtc.TrackRemote, pub *lksdk.RemoteTrackPublication, participant *lksdk.RemoteParticipant) {
 				logger.Infow("track unsubscribed",
@@ -810,6 +1152,10 @@ func joinRoom(ctx context.Context, cmd *cli.Command) error {
 				)
 			},
 		},
+		OnParticipantConnected: func(participant *lksdk.RemoteParticipant) {
+			logger.Infow("participant connected", "participant", participant.Identity())
+			observe(joinEventParticipantConnected)
+		},
 		OnRoomMetadataChanged: func(metadata string) {
 			logger.Infow("room metadata changed", "metadata", metadata)
 		},
@@ -821,16 +1167,27 @@ func joinRoom(ctx context.Context, cmd *cli.Command) error {
 		},
 		OnDisconnected: func() {
 			logger.Infow("disconnected from room")
-			close(done)
+			exit()
 		},
 	}
-	room, err := lksdk.ConnectToRoom(pc.URL, lksdk.ConnectInfo{
-		APIKey:              pc.APIKey,
-		APISecret:           pc.APISecret,
-		RoomName:            roomName,
-		ParticipantIdentity: participantIdentity,
-	}, roomCB)
-	if err != nil {
+	var connectOpts []lksdk.ConnectOption
+	if cmd.Bool("force-relay") {
+		connectOpts = append(connectOpts, lksdk.WithICETransportPolicy(webrtc.ICETransportPolicyRelay))
+	}
+	connectTimeout := cmd.Duration("connect-timeout")
+	room, err := util.CallWithTimeout(connectTimeout, func() (*lksdk.Room, error) {
+		return lksdk.ConnectToRoom(pc.APIURL(), lksdk.ConnectInfo{
+			APIKey:                pc.APIKey,
+			APISecret:             pc.APISecret,
+			RoomName:              roomName,
+			ParticipantIdentity:   participantIdentity,
+			ParticipantMetadata:   cmd.String("participant-metadata"),
+			ParticipantAttributes: attributes,
+		}, roomCB, connectOpts...)
+	}, (*lksdk.Room).Disconnect)
+	if errors.Is(err, util.ErrTimeout) {
+		return fmt.Errorf("failed to connect within %s", connectTimeout)
+	} else if err != nil {
 		return err
 	}
 	defer room.Disconnect()
@@ -845,24 +1202,46 @@ func joinRoom(ctx context.Context, cmd *cli.Command) error {
 		}
 	}
 
+	if cmd.Bool("publish-mic") {
+		if err = publishMicrophone(ctx, room); err != nil {
+			return fmt.Errorf("failed to publish microphone: %w", err)
+		}
+	}
+
 	exitAfterPublish := cmd.Bool("exit-after-publish")
 	if publish := cmd.StringSlice("publish"); publish != nil {
+		if publish, err = expandPublishGlobs(publish); err != nil {
+			return err
+		}
 		fps := cmd.Float("fps")
-		for _, pub := range publish {
-			onPublishComplete := func(pub *lksdk.LocalTrackPublication) {
-				if exitAfterPublish {
-					close(done)
-					return
-				}
-				if pub != nil {
-					fmt.Printf("finished writing %s\n", pub.Name())
-					_ = room.LocalParticipant.UnpublishTrack(pub.SID())
-				}
+		onPublishComplete := func(pub *lksdk.LocalTrackPublication) {
+			if exitAfterPublish {
+				exit()
+				return
 			}
-			if err = handlePublish(room, pub, fps, onPublishComplete); err != nil {
-				return err
+			if pub != nil {
+				fmt.Printf("finished writing %s\n", pub.Name())
+				_ = room.LocalParticipant.UnpublishTrack(pub.SID())
 			}
 		}
+
+		// Publish all tracks at once so one slow source doesn't hold up the rest,
+		// then report failures in the order they were given
+		var wg sync.WaitGroup
+		errs := make([]error, len(publish))
+		for i, pub := range publish {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := handlePublish(room, pub, fps, onPublishComplete); err != nil {
+					errs[i] = fmt.Errorf("failed to publish %s: %w", pub, err)
+				}
+			}()
+		}
+		wg.Wait()
+		if err = errors.Join(errs...); err != nil {
+			return err
+		}
 	}
 
 	publishPacket := func(p lksdk.DataPacket) error {
@@ -870,7 +1249,7 @@ func joinRoom(ctx context.Context, cmd *cli.Command) error {
 			return err
 		}
 		if exitAfterPublish {
-			close(done)
+			exit()
 		}
 		return nil
 	}
@@ -885,12 +1264,97 @@ func joinRoom(ctx context.Context, cmd *cli.Command) error {
 		}
 	}
 
+	// only started once publishing has succeeded, so that a failure above
+	// doesn't leave an egress running
+	if recordOutput != "" {
+		egressID, err := startParticipantRecording(ctx, room, recordOutput)
+		if err != nil {
+			return fmt.Errorf("failed to start recording: %w", err)
+		}
+		defer stopParticipantRecording(ctx, egressID)
+	}
+
 	<-done
+	if n := observed.Load(); exitEvent != "" && n < exitCount {
+		return fmt.Errorf("observed %d of %d %s events before exiting", n, exitCount, exitEvent)
+	}
 	return nil
 }
 
-func listParticipants(ctx context.Context, cmd *cli.Command) error {
+// startParticipantRecording starts a participant egress of the local
+// participant, to a file or, when output is a url, a stream.
+func startParticipantRecording(ctx context.Context, room *lksdk.Room, output string) (string, error) {
+	req := &livekit.ParticipantEgressRequest{
+		RoomName: room.Name(),
+		Identity: room.LocalParticipant.Identity(),
+	}
+	if strings.Contains(output, "://") {
+		req.StreamOutputs = []*livekit.StreamOutput{{Urls: []string{output}}}
+	} else {
+		req.FileOutputs = []*livekit.EncodedFileOutput{{Filepath: output}}
+	}
+	info, err := egressClient.StartParticipantEgress(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	fmt.Println("Recording with egress", info.EgressId)
+	return info.EgressId, nil
+}
+
+func stopParticipantRecording(ctx context.Context, egressID string) {
+	// the command context is cancelled on interrupt, which is when this
+	// usually runs
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	if _, err := egressClient.StopEgress(ctx, &livekit.StopEgressRequest{EgressId: egressID}); err != nil {
+		logger.Errorw("failed to stop recording", err, "egressID", egressID)
+		return
+	}
+	fmt.Println("Stopped recording egress", egressID)
+}
+
+func listParticipants(ctx context.Context, cmd *cli.Command) (*listing, error) {
 	roomName, err := extractArg(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := roomClient.ListParticipants(ctx, &livekit.ListParticipantsRequest{
+		Room: roomName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &listing{data: res, print: func() error {
+		if cmd.IsSet("template") {
+			return util.PrintTemplate(cmd.String("template"), res.Participants...)
+		} else if structuredOutput(cmd) {
+			printOutput(res)
+			return nil
+		}
+
+		table := util.CreateTable().Headers("Identity", "SID", "State", "Kind", "Tracks", "Joined At")
+		for _, p := range res.Participants {
+			var joinedAt string
+			if p.JoinedAt != 0 {
+				joinedAt = fmt.Sprint(time.Unix(p.JoinedAt, 0))
+			}
+			table.Row(
+				p.Identity,
+				p.Sid,
+				p.State.String(),
+				p.Kind.String(),
+				fmt.Sprintf("%d", len(p.Tracks)),
+				joinedAt,
+			)
+		}
+		return printTable(table)
+	}}, nil
+}
+
+func countParticipants(ctx context.Context, cmd *cli.Command) error {
+	roomName, err := extractFlagOrArg(cmd, "room")
 	if err != nil {
 		return err
 	}
@@ -902,8 +1366,22 @@ func listParticipants(ctx context.Context, cmd *cli.Command) error {
 		return err
 	}
 
+	count := 0
 	for _, p := range res.Participants {
-		fmt.Printf("%s (%s)\t tracks: %d\n", p.Identity, p.State.String(), len(p.Tracks))
+		if cmd.Bool("publishers-only") && len(p.Tracks) == 0 {
+			continue
+		}
+		count++
+	}
+	fmt.Println(count)
+
+	if cmd.Bool("exit-code") {
+		if cmd.IsSet("min") && count < int(cmd.Int("min")) {
+			return cli.Exit("", 1)
+		}
+		if cmd.IsSet("max") && count > int(cmd.Int("max")) {
+			return cli.Exit("", 1)
+		}
 	}
 	return nil
 }
@@ -934,6 +1412,9 @@ func getParticipant(ctx context.Context, cmd *cli.Command) error {
 		return err
 	}
 
+	if cmd.IsSet("template") {
+		return util.PrintTemplate(cmd.String("template"), res)
+	}
 	util.PrintJSON(res)
 
 	return nil
@@ -946,6 +1427,11 @@ func updateParticipant(ctx context.Context, cmd *cli.Command) error {
 	if metadata == "" && permissions == "" {
 		return fmt.Errorf("either metadata or permissions must be set")
 	}
+	if metadata != "" && cmd.Bool("metadata-json") {
+		if err := util.ValidateJSON(metadata); err != nil {
+			return fmt.Errorf("metadata: %w", err)
+		}
+	}
 
 	req := &livekit.UpdateParticipantRequest{
 		Room:     roomName,
@@ -996,6 +1482,48 @@ func removeParticipant(ctx context.Context, cmd *cli.Command) error {
 	return nil
 }
 
+func muteAllParticipants(ctx context.Context, cmd *cli.Command) error {
+	roomName, err := extractFlagOrArg(cmd, "room")
+	if err != nil {
+		return err
+	}
+	except := cmd.StringSlice("except")
+	includeVideo := cmd.Bool("video")
+
+	res, err := roomClient.ListParticipants(ctx, &livekit.ListParticipantsRequest{
+		Room: roomName,
+	})
+	if err != nil {
+		return err
+	}
+
+	muted := 0
+	var errs []error
+	for _, p := range res.Participants {
+		if slices.Contains(except, p.Identity) {
+			continue
+		}
+		for _, t := range p.Tracks {
+			if t.Muted || !(t.Type == livekit.TrackType_AUDIO || (includeVideo && t.Type == livekit.TrackType_VIDEO)) {
+				continue
+			}
+			_, err := roomClient.MutePublishedTrack(ctx, &livekit.MuteRoomTrackRequest{
+				Room:     roomName,
+				Identity: p.Identity,
+				TrackSid: t.Sid,
+				Muted:    true,
+			})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s %s: %w", p.Identity, t.Sid, err))
+				continue
+			}
+			muted++
+		}
+	}
+	fmt.Printf("Muted %d tracks in room %s\n", muted, roomName)
+	return errors.Join(errs...)
+}
+
 func muteTrack(ctx context.Context, cmd *cli.Command) error {
 	roomName, identity := participantInfoFromFlags(cmd)
 	muted := (!cmd.IsSet("m") && !cmd.IsSet("u")) || cmd.Bool("m") || !cmd.Bool("u")
@@ -1003,27 +1531,64 @@ func muteTrack(ctx context.Context, cmd *cli.Command) error {
 	if trackSid == "" {
 		trackSid = cmd.Args().First()
 	}
-	_, err := roomClient.MutePublishedTrack(ctx, &livekit.MuteRoomTrackRequest{
-		Room:     roomName,
-		Identity: identity,
-		TrackSid: trackSid,
-		Muted:    muted,
-	})
-	if err != nil {
-		return err
+
+	allTracks := cmd.Bool("all-tracks") || cmd.IsSet("source")
+	if trackSid != "" && allTracks {
+		return errors.New("TRACK_SID cannot be combined with --all-tracks or --source")
+	} else if trackSid == "" && !allTracks {
+		return errors.New("one of TRACK_SID, --all-tracks or --source is required")
+	}
+
+	// Without a SID, act on all of the participant's tracks, or those matching --source
+	trackSids := []string{trackSid}
+	if trackSid == "" {
+		sources, err := parseTrackSources(cmd.StringSlice("source"))
+		if err != nil {
+			return err
+		}
+		if trackSids, err = resolveTrackSIDs(ctx, roomName, identity, sources); err != nil {
+			return err
+		}
 	}
 
 	verb := "muted"
-	if !cmd.Bool("muted") {
+	if !muted {
 		verb = "unmuted"
 	}
-	fmt.Println(verb, "track: ", trackSid)
+	for _, sid := range trackSids {
+		_, err := roomClient.MutePublishedTrack(ctx, &livekit.MuteRoomTrackRequest{
+			Room:     roomName,
+			Identity: identity,
+			TrackSid: sid,
+			Muted:    muted,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Println(verb, "track: ", sid)
+	}
 	return nil
 }
 
 func updateSubscriptions(ctx context.Context, cmd *cli.Command) error {
 	roomName, identity := participantInfoFromFlags(cmd)
 	trackSids := cmd.StringSlice("track")
+	if len(trackSids) == 0 {
+		trackSids = cmd.Args().Slice()
+	}
+	if len(trackSids) == 0 && cmd.IsSet("source") {
+		publisher := cmd.String("publisher")
+		if publisher == "" {
+			return errors.New("--publisher is required when resolving tracks by --source")
+		}
+		sources, err := parseTrackSources(cmd.StringSlice("source"))
+		if err != nil {
+			return err
+		}
+		if trackSids, err = resolveTrackSIDs(ctx, roomName, publisher, sources); err != nil {
+			return err
+		}
+	}
 	_, err := roomClient.UpdateSubscriptions(ctx, &livekit.UpdateSubscriptionsRequest{
 		Room:      roomName,
 		Identity:  identity,
@@ -1044,18 +1609,38 @@ func updateSubscriptions(ctx context.Context, cmd *cli.Command) error {
 
 func sendData(ctx context.Context, cmd *cli.Command) error {
 	roomName, _ := participantInfoFromFlags(cmd)
+	kind := livekit.DataPacket_RELIABLE
+	if cmd.Bool("lossy") {
+		kind = livekit.DataPacket_LOSSY
+	}
+	if file := cmd.String("from-jsonl"); file != "" {
+		return sendDataFromJSONL(ctx, roomName, file, cmd.Float("rate"), kind)
+	}
 	identities := cmd.StringSlice("identity")
-	data := cmd.String("data")
-	if data == "" {
-		data = cmd.Args().First()
+	sids := append(cmd.StringSlice("sid"), cmd.StringSlice("participantID")...)
+	data := []byte(cmd.String("data"))
+	if len(data) == 0 {
+		data = []byte(cmd.Args().First())
+	}
+	if file := cmd.String("data-file"); file != "" {
+		if len(data) != 0 {
+			return errors.New("DATA cannot be combined with --data-file")
+		}
+		var err error
+		if data, err = os.ReadFile(file); err != nil {
+			return err
+		}
+	}
+	if len(data) == 0 {
+		return errors.New("no data to send")
 	}
 	topic := cmd.String("topic")
 	req := &livekit.SendDataRequest{
 		Room:                  roomName,
-		Data:                  []byte(data),
+		Data:                  data,
+		Kind:                  kind,
 		DestinationIdentities: identities,
-		// deprecated
-		DestinationSids: cmd.StringSlice("participantID"),
+		DestinationSids:       sids,
 	}
 	if topic != "" {
 		req.Topic = &topic
@@ -1065,10 +1650,137 @@ func sendData(ctx context.Context, cmd *cli.Command) error {
 		return err
 	}
 
-	fmt.Println("successfully sent data to room", roomName)
+	if len(identities) == 0 && len(sids) == 0 {
+		fmt.Println("successfully broadcast data to room", roomName)
+	} else {
+		fmt.Println("successfully sent data to room", roomName)
+	}
+	return nil
+}
+
+type dataMessage struct {
+	Topic         string   `json:"topic"`
+	Identities    []string `json:"identities"`
+	PayloadBase64 string   `json:"payload_base64"`
+}
+
+func sendDataFromJSONL(ctx context.Context, roomName, file string, rate float64, kind livekit.DataPacket_Kind) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var interval time.Duration
+	if rate > 0 {
+		interval = time.Duration(float64(time.Second) / rate)
+	}
+
+	var sent, failed int
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if sent+failed > 0 && interval > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(interval):
+			}
+		}
+
+		var msg dataMessage
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			fmt.Fprintf(os.Stderr, "line %d: invalid message: %v\n", lineNum, err)
+			failed++
+			continue
+		}
+		payload, err := base64.StdEncoding.DecodeString(msg.PayloadBase64)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "line %d: invalid payload_base64: %v\n", lineNum, err)
+			failed++
+			continue
+		}
+		req := &livekit.SendDataRequest{
+			Room:                  roomName,
+			Data:                  payload,
+			Kind:                  kind,
+			DestinationIdentities: msg.Identities,
+		}
+		if msg.Topic != "" {
+			req.Topic = &msg.Topic
+		}
+		if _, err = roomClient.SendData(ctx, req); err != nil {
+			fmt.Fprintf(os.Stderr, "line %d: %v\n", lineNum, err)
+			failed++
+			continue
+		}
+		sent++
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	fmt.Printf("sent %d message(s) to room %s, %d failed\n", sent, roomName, failed)
+	if failed > 0 {
+		return fmt.Errorf("failed to send %d message(s)", failed)
+	}
 	return nil
 }
 
+// Look up the SIDs of all tracks published by a participant which match any of
+// the given sources. If no sources are given, all of the participant's tracks match.
+func resolveTrackSIDs(ctx context.Context, roomName, identity string, sources []livekit.TrackSource) ([]string, error) {
+	p, err := roomClient.GetParticipant(ctx, &livekit.RoomParticipantIdentity{
+		Room:     roomName,
+		Identity: identity,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var sids []string
+	for _, track := range p.Tracks {
+		if len(sources) == 0 || slices.Contains(sources, track.Source) {
+			sids = append(sids, track.Sid)
+		}
+	}
+	if len(sids) == 0 {
+		return nil, fmt.Errorf("participant %s has no matching tracks", identity)
+	}
+	return sids, nil
+}
+
+func parseTrackSources(names []string) ([]livekit.TrackSource, error) {
+	sources := make([]livekit.TrackSource, 0, len(names))
+	for _, name := range names {
+		source, err := parseTrackSource(name)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+func parseTrackSource(name string) (livekit.TrackSource, error) {
+	switch strings.ToLower(strings.ReplaceAll(name, "-", "_")) {
+	case "camera":
+		return livekit.TrackSource_CAMERA, nil
+	case "microphone", "mic":
+		return livekit.TrackSource_MICROPHONE, nil
+	case "screen_share", "screenshare", "screen":
+		return livekit.TrackSource_SCREEN_SHARE, nil
+	case "screen_share_audio", "screenshare_audio", "screen_audio":
+		return livekit.TrackSource_SCREEN_SHARE_AUDIO, nil
+	default:
+		return livekit.TrackSource_UNKNOWN, fmt.Errorf("invalid source: %s", name)
+	}
+}
+
 func participantInfoFromFlags(c *cli.Command) (string, string) {
 	return c.String("room"), c.String("identity")
 }
@@ -1076,7 +1788,8 @@ func participantInfoFromFlags(c *cli.Command) (string, string) {
 func participantInfoFromArgOrFlags(c *cli.Command) (string, string) {
 	room := c.String("room")
 	id := c.String("identity")
-	if id == "" {
+	// an argument takes precedence over a value from config defaults
+	if id == "" || (defaultedFlags["identity"] && c.Args().Present()) {
 		id = c.Args().First()
 	}
 	return room, id
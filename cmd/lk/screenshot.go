@@ -0,0 +1,104 @@
+// Copyright 2024 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+const (
+	// how long the page is given to connect and render before capture
+	screenshotRenderBudget = 10 * time.Second
+	screenshotTimeout      = 60 * time.Second
+)
+
+var headlessBrowsers = []string{
+	"chromium",
+	"chromium-browser",
+	"google-chrome",
+	"google-chrome-stable",
+	"chrome",
+	"microsoft-edge",
+}
+
+// findHeadlessBrowser returns the path of a Chromium-based browser able to run
+// headless, honoring $CHROME_PATH when set.
+func findHeadlessBrowser() (string, error) {
+	if p := os.Getenv("CHROME_PATH"); p != "" {
+		return exec.LookPath(p)
+	}
+	for _, name := range headlessBrowsers {
+		if p, err := exec.LookPath(name); err == nil {
+			return p, nil
+		}
+	}
+	if runtime.GOOS == "darwin" {
+		for _, p := range []string{
+			"/Applications/Google Chrome.app/Contents/MacOS/Google Chrome",
+			"/Applications/Chromium.app/Contents/MacOS/Chromium",
+		} {
+			if _, err := os.Stat(p); err == nil {
+				return p, nil
+			}
+		}
+	}
+	return "", errors.New("no headless browser found, install Chrome or Chromium or set CHROME_PATH")
+}
+
+// captureScreenshot renders pageURL in a headless browser at the given size
+// and saves a PNG to file.
+func captureScreenshot(ctx context.Context, pageURL, file string, width, height int32) error {
+	browserPath, err := findHeadlessBrowser()
+	if err != nil {
+		return err
+	}
+	file, err = filepath.Abs(file)
+	if err != nil {
+		return err
+	}
+
+	// a file left from an earlier run would pass for this one's screenshot
+	if err = os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, screenshotTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, browserPath,
+		"--headless",
+		"--disable-gpu",
+		"--hide-scrollbars",
+		"--mute-audio",
+		"--autoplay-policy=no-user-gesture-required",
+		fmt.Sprintf("--window-size=%d,%d", width, height),
+		fmt.Sprintf("--virtual-time-budget=%d", screenshotRenderBudget.Milliseconds()),
+		"--screenshot="+file,
+		pageURL,
+	).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("browser failed: %w\n%s", err, out)
+	}
+	if _, err = os.Stat(file); err != nil {
+		return fmt.Errorf("browser did not write a screenshot: %w\n%s", err, out)
+	}
+	return nil
+}
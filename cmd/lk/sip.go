@@ -16,19 +16,39 @@ package main
 
 import (
 	"context"
+	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/twitchtv/twirp"
+	"github.com/urfave/cli/v3"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+
 	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/utils"
 	lksdk "github.com/livekit/server-sdk-go/v2"
-	"github.com/urfave/cli/v3"
+
+	"github.com/livekit/livekit-cli/pkg/util"
 )
 
 //lint:file-ignore SA1019 we still support older APIs for compatibility
 
 var (
+	skipNumberValidationFlag = &cli.BoolFlag{
+		Name:  "skip-number-validation",
+		Usage: "Allow trunk numbers which are not in E.164 format, for non-standard dialing plans",
+	}
+
 	SIPCommands = []*cli.Command{
 		{
 			Name:  "sip",
@@ -42,14 +62,22 @@ var (
 						{
 							Name:   "list",
 							Usage:  "List all inbound SIP Trunks",
-							Action: listSipInboundTrunk,
-							Flags:  []cli.Flag{jsonFlag},
+							Action: forEachProject(nil, listSipInboundTrunk),
+							Flags:  []cli.Flag{jsonFlag, templateFlag[livekit.SIPInboundTrunkInfo]()},
 						},
 						{
 							Name:      "create",
 							Usage:     "Create an inbound SIP Trunk",
 							Action:    createSIPInboundTrunk,
 							ArgsUsage: RequestDesc[livekit.CreateSIPInboundTrunkRequest](),
+							Flags:     []cli.Flag{skipNumberValidationFlag},
+						},
+						{
+							Name:      "get",
+							Usage:     "Print the configuration of an inbound SIP Trunk",
+							Action:    getSIPInboundTrunk,
+							ArgsUsage: "SIPTrunk ID",
+							Flags:     []cli.Flag{jsonFlag},
 						},
 						{
 							Name:      "delete",
@@ -67,14 +95,41 @@ var (
 						{
 							Name:   "list",
 							Usage:  "List all outbound SIP Trunk",
-							Action: listSipOutboundTrunk,
-							Flags:  []cli.Flag{jsonFlag},
+							Action: forEachProject(nil, listSipOutboundTrunk),
+							Flags:  []cli.Flag{jsonFlag, templateFlag[livekit.SIPOutboundTrunkInfo]()},
 						},
 						{
 							Name:      "create",
 							Usage:     "Create a outbound SIP Trunk",
 							Action:    createSIPOutboundTrunk,
 							ArgsUsage: RequestDesc[livekit.CreateSIPOutboundTrunkRequest](),
+							Flags:     []cli.Flag{skipNumberValidationFlag},
+						},
+						{
+							Name:      "get",
+							Usage:     "Print the configuration of an outbound SIP Trunk",
+							Action:    getSIPOutboundTrunk,
+							ArgsUsage: "SIPTrunk ID",
+							Flags:     []cli.Flag{jsonFlag},
+						},
+						{
+							Name:      "test",
+							Usage:     "Place a short test call through an outbound SIP Trunk and report the result",
+							Before:    createRoomClient,
+							Action:    testSIPOutboundTrunk,
+							ArgsUsage: "SIPTrunk ID to test",
+							Flags: []cli.Flag{
+								&cli.StringFlag{
+									Name:     "to",
+									Required: true,
+									Usage:    "Phone `NUMBER` to call",
+								},
+								&cli.DurationFlag{
+									Name:  "timeout",
+									Usage: "How long to wait for the call to be answered",
+									Value: 30 * time.Second,
+								},
+							},
 						},
 						{
 							Name:      "delete",
@@ -92,14 +147,28 @@ var (
 						{
 							Name:   "list",
 							Usage:  "List all SIP Dispatch Rule",
-							Action: listSipDispatchRule,
-							Flags:  []cli.Flag{jsonFlag},
+							Action: forEachProject(nil, listSipDispatchRule),
+							Flags:  []cli.Flag{jsonFlag, templateFlag[livekit.SIPDispatchRuleInfo]()},
 						},
 						{
 							Name:      "create",
 							Usage:     "Create a SIP Dispatch Rule",
 							Action:    createSIPDispatchRule,
 							ArgsUsage: RequestDesc[livekit.CreateSIPDispatchRuleRequest](),
+							Flags: []cli.Flag{
+								&cli.StringFlag{
+									Name:  "pin",
+									Usage: "`PIN` callers must enter to join, overriding any pin in the request",
+								},
+								&cli.StringSliceFlag{
+									Name:  "agent-name",
+									Usage: "`NAME` of an agent to dispatch to rooms created by this rule, can be used multiple times",
+								},
+								&cli.StringSliceFlag{
+									Name:  "agent-metadata",
+									Usage: "`METADATA` for the agent given by the matching --agent-name, in the same order",
+								},
+							},
 						},
 						{
 							Name:      "delete",
@@ -118,6 +187,34 @@ var (
 							Usage:     "Create a SIP Participant",
 							Action:    createSIPParticipant,
 							ArgsUsage: RequestDesc[livekit.CreateSIPParticipantRequest](),
+							Description: "With --csv, REQUEST_JSON holds the fields shared by every call, such as the trunk and room,\n" +
+								"and each row of FILE dials one participant. Rows are formatted as CALL_TO,IDENTITY[,NAME]\n" +
+								"and an optional header row is skipped.",
+							Flags: []cli.Flag{
+								&cli.StringFlag{
+									Name:      "csv",
+									Usage:     "Dial every destination listed in CSV `FILE`",
+									TakesFile: true,
+								},
+								&cli.IntFlag{
+									Name:  "parallel",
+									Usage: "`NUMBER` of calls to place at once with --csv",
+									Value: 1,
+								},
+							},
+						},
+						{
+							Name:   "list",
+							Usage:  "List active SIP calls",
+							Before: createRoomClient,
+							Action: listSIPParticipants,
+							Flags: []cli.Flag{
+								&cli.StringFlag{
+									Name:  "room",
+									Usage: "Only list calls in room `NAME`",
+								},
+								jsonFlag,
+							},
 						},
 						{
 							Name:   "transfer",
@@ -147,7 +244,7 @@ var (
 			Hidden: true, // deprecated: use `sip trunk list`
 			Name:   "list-sip-trunk",
 			Usage:  "List all SIP trunk",
-			Action: listSipTrunk,
+			Action: printListing(listSipTrunk),
 		},
 		{
 			Hidden: true, // deprecated: use `sip trunk delete`
@@ -175,7 +272,7 @@ var (
 			Hidden: true, // deprecated: use `sip dispatch list`
 			Name:   "list-sip-dispatch-rule",
 			Usage:  "List all SIP Dispatch Rule",
-			Action: listSipDispatchRule,
+			Action: printListing(listSipDispatchRule),
 		},
 		{
 			Hidden: true, // deprecated: use `sip dispatch delete`
@@ -207,7 +304,7 @@ func createSIPClient(cmd *cli.Command) (*lksdk.SIPClient, error) {
 	if err != nil {
 		return nil, err
 	}
-	return lksdk.NewSIPClient(pc.URL, pc.APIKey, pc.APISecret, withDefaultClientOpts(pc)...), nil
+	return lksdk.NewSIPClient(pc.APIURL(), pc.APIKey, pc.APISecret, withDefaultClientOpts(pc)...), nil
 }
 
 func createSIPInboundTrunk(ctx context.Context, cmd *cli.Command) error {
@@ -215,7 +312,15 @@ func createSIPInboundTrunk(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return err
 	}
-	return createAndPrintReqs(ctx, cmd, cli.CreateSIPInboundTrunk, printSIPInboundTrunkID)
+	create := func(ctx context.Context, req *livekit.CreateSIPInboundTrunkRequest) (*livekit.SIPInboundTrunkInfo, error) {
+		if !cmd.Bool("skip-number-validation") {
+			if err := validateSIPNumbers(req.GetTrunk().GetNumbers()); err != nil {
+				return nil, err
+			}
+		}
+		return cli.CreateSIPInboundTrunk(ctx, req)
+	}
+	return createAndPrintReqs(ctx, cmd, create, printSIPInboundTrunkID)
 }
 
 func createSIPOutboundTrunk(ctx context.Context, cmd *cli.Command) error {
@@ -223,7 +328,28 @@ func createSIPOutboundTrunk(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return err
 	}
-	return createAndPrintReqs(ctx, cmd, cli.CreateSIPOutboundTrunk, printSIPOutboundTrunkID)
+	create := func(ctx context.Context, req *livekit.CreateSIPOutboundTrunkRequest) (*livekit.SIPOutboundTrunkInfo, error) {
+		if !cmd.Bool("skip-number-validation") {
+			if err := validateSIPNumbers(req.GetTrunk().GetNumbers()); err != nil {
+				return nil, err
+			}
+		}
+		return cli.CreateSIPOutboundTrunk(ctx, req)
+	}
+	return createAndPrintReqs(ctx, cmd, create, printSIPOutboundTrunkID)
+}
+
+var e164Number = regexp.MustCompile(`^\+[1-9]\d{1,14}$`)
+
+// validateSIPNumbers checks that every number is in E.164 format, so that
+// mistakes surface here rather than as failed calls.
+func validateSIPNumbers(numbers []string) error {
+	for _, n := range numbers {
+		if !e164Number.MatchString(n) {
+			return fmt.Errorf("number %q is not in E.164 format, e.g. +15105550100 (use --skip-number-validation to allow it)", n)
+		}
+	}
+	return nil
 }
 
 func userPass(user string, hasPass bool) string {
@@ -237,13 +363,13 @@ func userPass(user string, hasPass bool) string {
 	return user + " / " + passStr
 }
 
-func listSipTrunk(ctx context.Context, cmd *cli.Command) error {
+func listSipTrunk(ctx context.Context, cmd *cli.Command) (*listing, error) {
 	cli, err := createSIPClient(cmd)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	//lint:ignore SA1019 we still support it
-	return listAndPrint(ctx, cmd, cli.ListSIPTrunk, &livekit.ListSIPTrunkRequest{}, []string{
+	return listItems(ctx, cmd, cli.ListSIPTrunk, &livekit.ListSIPTrunkRequest{}, []string{
 		"SipTrunkID", "Name", "Kind", "Number",
 		"AllowAddresses", "AllowNumbers", "InboundAuth",
 		"OutboundAddress", "OutboundAuth",
@@ -262,12 +388,12 @@ func listSipTrunk(ctx context.Context, cmd *cli.Command) error {
 	})
 }
 
-func listSipInboundTrunk(ctx context.Context, cmd *cli.Command) error {
+func listSipInboundTrunk(ctx context.Context, cmd *cli.Command) (*listing, error) {
 	cli, err := createSIPClient(cmd)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return listAndPrint(ctx, cmd, cli.ListSIPInboundTrunk, &livekit.ListSIPInboundTrunkRequest{}, []string{
+	return listItems(ctx, cmd, cli.ListSIPInboundTrunk, &livekit.ListSIPInboundTrunkRequest{}, []string{
 		"SipTrunkID", "Name", "Numbers",
 		"AllowedAddresses", "AllowedNumbers",
 		"Authentication",
@@ -284,12 +410,12 @@ func listSipInboundTrunk(ctx context.Context, cmd *cli.Command) error {
 	})
 }
 
-func listSipOutboundTrunk(ctx context.Context, cmd *cli.Command) error {
+func listSipOutboundTrunk(ctx context.Context, cmd *cli.Command) (*listing, error) {
 	cli, err := createSIPClient(cmd)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return listAndPrint(ctx, cmd, cli.ListSIPOutboundTrunk, &livekit.ListSIPOutboundTrunkRequest{}, []string{
+	return listItems(ctx, cmd, cli.ListSIPOutboundTrunk, &livekit.ListSIPOutboundTrunkRequest{}, []string{
 		"SipTrunkID", "Name",
 		"Address", "Transport",
 		"Numbers",
@@ -308,6 +434,102 @@ func listSipOutboundTrunk(ctx context.Context, cmd *cli.Command) error {
 	})
 }
 
+func getSIPInboundTrunk(ctx context.Context, cmd *cli.Command) error {
+	id, err := extractArg(cmd)
+	if err != nil {
+		return err
+	}
+	cli, err := createSIPClient(cmd)
+	if err != nil {
+		return err
+	}
+	trunks, err := cli.GetSIPInboundTrunksByIDs(ctx, []string{id})
+	if err != nil {
+		return err
+	}
+	if len(trunks) == 0 || trunks[0] == nil {
+		return fmt.Errorf("inbound trunk %s not found", id)
+	}
+	t := trunks[0]
+	if structuredOutput(cmd) {
+		printOutputRedacted(t)
+		return nil
+	}
+
+	fmt.Printf("SipTrunkID: %s\n", t.SipTrunkId)
+	fmt.Printf("Name: %s\n", t.Name)
+	fmt.Printf("Numbers: %s\n", strings.Join(t.Numbers, ","))
+	fmt.Printf("AllowedAddresses: %s\n", strings.Join(t.AllowedAddresses, ","))
+	fmt.Printf("AllowedNumbers: %s\n", strings.Join(t.AllowedNumbers, ","))
+	fmt.Printf("Authentication: %s\n", userPass(t.AuthUsername, t.AuthPassword != ""))
+	fmt.Printf("IncludeHeaders: %s\n", t.IncludeHeaders)
+	if t.RingingTimeout != nil {
+		fmt.Printf("RingingTimeout: %s\n", t.RingingTimeout.AsDuration())
+	}
+	if t.MaxCallDuration != nil {
+		fmt.Printf("MaxCallDuration: %s\n", t.MaxCallDuration.AsDuration())
+	}
+	fmt.Printf("KrispEnabled: %t\n", t.KrispEnabled)
+	fmt.Printf("Metadata: %s\n", t.Metadata)
+	printHeaderMaps(t.Headers, t.HeadersToAttributes, t.AttributesToHeaders)
+	return nil
+}
+
+func getSIPOutboundTrunk(ctx context.Context, cmd *cli.Command) error {
+	id, err := extractArg(cmd)
+	if err != nil {
+		return err
+	}
+	cli, err := createSIPClient(cmd)
+	if err != nil {
+		return err
+	}
+	trunks, err := cli.GetSIPOutboundTrunksByIDs(ctx, []string{id})
+	if err != nil {
+		return err
+	}
+	if len(trunks) == 0 || trunks[0] == nil {
+		return fmt.Errorf("outbound trunk %s not found", id)
+	}
+	t := trunks[0]
+	if structuredOutput(cmd) {
+		printOutputRedacted(t)
+		return nil
+	}
+
+	fmt.Printf("SipTrunkID: %s\n", t.SipTrunkId)
+	fmt.Printf("Name: %s\n", t.Name)
+	fmt.Printf("Address: %s\n", t.Address)
+	fmt.Printf("Transport: %s\n", strings.TrimPrefix(t.Transport.String(), "SIP_TRANSPORT_"))
+	fmt.Printf("Numbers: %s\n", strings.Join(t.Numbers, ","))
+	fmt.Printf("Authentication: %s\n", userPass(t.AuthUsername, t.AuthPassword != ""))
+	fmt.Printf("IncludeHeaders: %s\n", t.IncludeHeaders)
+	fmt.Printf("Metadata: %s\n", t.Metadata)
+	printHeaderMaps(t.Headers, t.HeadersToAttributes, t.AttributesToHeaders)
+	return nil
+}
+
+// printHeaderMaps prints the SIP header mappings of a trunk, one entry per
+// line in key order.
+func printHeaderMaps(headers, headersToAttributes, attributesToHeaders map[string]string) {
+	for _, m := range []struct {
+		name    string
+		entries map[string]string
+	}{
+		{"Headers", headers},
+		{"HeadersToAttributes", headersToAttributes},
+		{"AttributesToHeaders", attributesToHeaders},
+	} {
+		if len(m.entries) == 0 {
+			continue
+		}
+		fmt.Printf("%s:\n", m.name)
+		for _, k := range slices.Sorted(maps.Keys(m.entries)) {
+			fmt.Printf("  %s: %s\n", k, m.entries[k])
+		}
+	}
+}
+
 func deleteSIPTrunk(ctx context.Context, cmd *cli.Command) error {
 	cli, err := createSIPClient(cmd)
 	if err != nil {
@@ -357,7 +579,79 @@ func createSIPDispatchRule(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return err
 	}
-	return createAndPrintReqs(ctx, cmd, cli.CreateSIPDispatchRule, printSIPDispatchRuleID)
+	pin := cmd.String("pin")
+	if cmd.IsSet("pin") {
+		if err = validateSIPPin(pin); err != nil {
+			return err
+		}
+	}
+	agents, err := sipDispatchAgents(cmd.StringSlice("agent-name"), cmd.StringSlice("agent-metadata"))
+	if err != nil {
+		return err
+	}
+	create := func(ctx context.Context, req *livekit.CreateSIPDispatchRuleRequest) (*livekit.SIPDispatchRuleInfo, error) {
+		if pin != "" {
+			if err := setSIPDispatchRulePin(req.Rule, pin); err != nil {
+				return nil, err
+			}
+		}
+		if len(agents) > 0 {
+			if req.RoomConfig == nil {
+				req.RoomConfig = &livekit.RoomConfiguration{}
+			}
+			req.RoomConfig.Agents = append(req.RoomConfig.Agents, agents...)
+		}
+		return cli.CreateSIPDispatchRule(ctx, req)
+	}
+	return createAndPrintReqs(ctx, cmd, create, printSIPDispatchRuleID)
+}
+
+// sipDispatchAgents pairs each agent name with the metadata given in the same
+// position, if any.
+func sipDispatchAgents(names, metadata []string) ([]*livekit.RoomAgentDispatch, error) {
+	if len(metadata) > 0 && len(metadata) != len(names) {
+		return nil, fmt.Errorf("got %d --agent-metadata values for %d --agent-name values", len(metadata), len(names))
+	}
+	agents := make([]*livekit.RoomAgentDispatch, 0, len(names))
+	for i, name := range names {
+		agent := &livekit.RoomAgentDispatch{AgentName: name}
+		if len(metadata) > 0 {
+			agent.Metadata = metadata[i]
+		}
+		agents = append(agents, agent)
+	}
+	return agents, nil
+}
+
+const (
+	minSIPPinLength = 4
+	maxSIPPinLength = 12
+)
+
+func validateSIPPin(pin string) error {
+	if len(pin) < minSIPPinLength || len(pin) > maxSIPPinLength {
+		return fmt.Errorf("pin must be between %d and %d digits", minSIPPinLength, maxSIPPinLength)
+	}
+	for _, c := range pin {
+		if c < '0' || c > '9' {
+			return errors.New("pin must contain only digits")
+		}
+	}
+	return nil
+}
+
+func setSIPDispatchRulePin(rule *livekit.SIPDispatchRule, pin string) error {
+	switch r := rule.GetRule().(type) {
+	case *livekit.SIPDispatchRule_DispatchRuleDirect:
+		r.DispatchRuleDirect.Pin = pin
+	case *livekit.SIPDispatchRule_DispatchRuleIndividual:
+		r.DispatchRuleIndividual.Pin = pin
+	case *livekit.SIPDispatchRule_DispatchRuleCallee:
+		r.DispatchRuleCallee.Pin = pin
+	default:
+		return errors.New("pin requires a direct, individual or callee dispatch rule")
+	}
+	return nil
 }
 
 func createSIPDispatchRuleLegacy(ctx context.Context, cmd *cli.Command) error {
@@ -368,12 +662,12 @@ func createSIPDispatchRuleLegacy(ctx context.Context, cmd *cli.Command) error {
 	return createAndPrintLegacy(ctx, cmd, cli.CreateSIPDispatchRule, printSIPDispatchRuleID)
 }
 
-func listSipDispatchRule(ctx context.Context, cmd *cli.Command) error {
+func listSipDispatchRule(ctx context.Context, cmd *cli.Command) (*listing, error) {
 	cli, err := createSIPClient(cmd)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return listAndPrint(ctx, cmd, cli.ListSIPDispatchRule, &livekit.ListSIPDispatchRuleRequest{}, []string{
+	return listItems(ctx, cmd, cli.ListSIPDispatchRule, &livekit.ListSIPDispatchRuleRequest{}, []string{
 		"SipDispatchRuleID", "Name", "SipTrunks", "Type", "RoomName", "Pin", "HidePhone",
 		"Attributes", "Metadata",
 	}, func(item *livekit.SIPDispatchRuleInfo) []string {
@@ -447,31 +741,255 @@ func createSIPParticipant(ctx context.Context, cmd *cli.Command) error {
 	if err != nil {
 		return err
 	}
+	if file := cmd.String("csv"); file != "" {
+		return createSIPParticipantsFromCSV(ctx, cmd, cli, file)
+	}
 	return createAndPrintReqs(ctx, cmd, func(ctx context.Context, req *livekit.CreateSIPParticipantRequest) (*livekit.SIPParticipantInfo, error) {
-		// CreateSIPParticipant will wait for LiveKit Participant to be created and that can take some time.
-		// Default deadline is too short, thus, we must set a higher deadline for it.
-		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
-		defer cancel()
-
-		return cli.CreateSIPParticipant(ctx, req)
+		return dialSIPParticipant(ctx, cli.CreateSIPParticipant, req, sipDialTimeout)
 	}, printSIPParticipantInfo)
 }
 
+type sipDestination struct {
+	CallTo   string
+	Identity string
+	Name     string
+}
+
+// readSIPDestinations parses rows of CALL_TO,IDENTITY[,NAME], skipping a
+// header row if there is one.
+func readSIPDestinations(r io.Reader) ([]sipDestination, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var dests []sipDestination
+	for first := true; ; first = false {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if first && len(rec) > 0 && slices.Contains([]string{"call_to", "sip_call_to", "to"}, strings.ToLower(rec[0])) {
+			continue
+		}
+		if len(rec) < 2 || len(rec) > 3 || rec[0] == "" || rec[1] == "" {
+			// report the physical line so it matches what an editor shows
+			line, _ := reader.FieldPos(0)
+			return nil, fmt.Errorf("line %d: expected CALL_TO,IDENTITY[,NAME]", line)
+		}
+		d := sipDestination{CallTo: rec[0], Identity: rec[1]}
+		if len(rec) == 3 {
+			d.Name = rec[2]
+		}
+		dests = append(dests, d)
+	}
+	if len(dests) == 0 {
+		return nil, errors.New("no destinations found")
+	}
+	return dests, nil
+}
+
+func createSIPParticipantsFromCSV(ctx context.Context, cmd *cli.Command, client *lksdk.SIPClient, file string) error {
+	base, err := ReadRequestArg[livekit.CreateSIPParticipantRequest](cmd)
+	if err != nil {
+		return err
+	}
+	parallel := int(cmd.Int("parallel"))
+	if parallel < 1 {
+		return errors.New("--parallel must be at least 1")
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	dests, err := readSIPDestinations(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+
+	infos := make([]*livekit.SIPParticipantInfo, len(dests))
+	errs := make([]error, len(dests))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, d := range dests {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			req := proto.Clone(base).(*livekit.CreateSIPParticipantRequest)
+			req.SipCallTo = d.CallTo
+			req.ParticipantIdentity = d.Identity
+			if d.Name != "" {
+				req.ParticipantName = d.Name
+			}
+			if errs[i] = req.Validate(); errs[i] == nil {
+				infos[i], errs[i] = dialSIPParticipant(ctx, client.CreateSIPParticipant, req, sipDialTimeout)
+			}
+		}()
+	}
+	wg.Wait()
+
+	failed := 0
+	table := util.CreateTable().Headers("Row", "CallTo", "Identity", "Result", "SIPCallID / Status")
+	for i, d := range dests {
+		result, detail := "OK", ""
+		if errs[i] != nil {
+			failed++
+			result, detail = "Failed", sipErrorStatus(errs[i])
+		} else {
+			detail = infos[i].SipCallId
+		}
+		table.Row(strconv.Itoa(i+1), d.CallTo, d.Identity, result, detail)
+	}
+	if err := printTable(table); err != nil {
+		return err
+	}
+	fmt.Printf("Created %d of %d SIP participants\n", len(dests)-failed, len(dests))
+	if failed > 0 {
+		return fmt.Errorf("%d calls failed", failed)
+	}
+	return nil
+}
+
+// sipErrorStatus describes a failed call by its SIP status when known.
+func sipErrorStatus(err error) string {
+	var terr twirp.Error
+	if errors.As(err, &terr) {
+		if code := terr.Meta("sip_status_code"); code != "" {
+			return strings.TrimSpace(code + " " + terr.Meta("sip_status"))
+		}
+		return terr.Msg()
+	}
+	return err.Error()
+}
+
+// CreateSIPParticipant will wait for LiveKit Participant to be created and that can take some time.
+// Default deadline is too short, thus, we must set a higher deadline for it.
+const sipDialTimeout = 30 * time.Second
+
+var errSIPDialCancelled = errors.New("dial cancelled")
+
+// dialSIPParticipant places the call, aborting the in-flight request as soon as
+// ctx is cancelled (e.g. by Ctrl-C) rather than waiting out the full timeout.
+func dialSIPParticipant(
+	ctx context.Context,
+	dial func(context.Context, *livekit.CreateSIPParticipantRequest) (*livekit.SIPParticipantInfo, error),
+	req *livekit.CreateSIPParticipantRequest,
+	timeout time.Duration,
+) (*livekit.SIPParticipantInfo, error) {
+	dialCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	info, err := dial(dialCtx, req)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return nil, errSIPDialCancelled
+		}
+		if errors.Is(dialCtx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("dial timed out after %s: %w", timeout, err)
+		}
+	}
+	return info, err
+}
+
 func createSIPParticipantLegacy(ctx context.Context, cmd *cli.Command) error {
 	cli, err := createSIPClient(cmd)
 	if err != nil {
 		return err
 	}
 	return createAndPrintLegacy(ctx, cmd, func(ctx context.Context, req *livekit.CreateSIPParticipantRequest) (*livekit.SIPParticipantInfo, error) {
-		// CreateSIPParticipant will wait for LiveKit Participant to be created and that can take some time.
-		// Default deadline is too short, thus, we must set a higher deadline for it.
-		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
-		defer cancel()
-
-		return cli.CreateSIPParticipant(ctx, req)
+		return dialSIPParticipant(ctx, cli.CreateSIPParticipant, req, sipDialTimeout)
 	}, printSIPParticipantInfo)
 }
 
+func testSIPOutboundTrunk(ctx context.Context, cmd *cli.Command) error {
+	trunkID, err := extractArg(cmd)
+	if err != nil {
+		return err
+	}
+	cli, err := createSIPClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	to := cmd.String("to")
+	timeout := cmd.Duration("timeout")
+	roomName := utils.NewGuid("sip-test-")
+	identity := "sip-test"
+
+	// Deleting the room hangs up the call, whatever state it reached
+	defer func() {
+		_, _ = roomClient.DeleteRoom(context.Background(), &livekit.DeleteRoomRequest{Room: roomName})
+	}()
+
+	fmt.Printf("Calling %s through trunk %s\n", to, trunkID)
+	createCtx, cancel := context.WithTimeout(ctx, timeout+10*time.Second)
+	defer cancel()
+	_, err = cli.CreateSIPParticipant(createCtx, &livekit.CreateSIPParticipantRequest{
+		SipTrunkId:          trunkID,
+		SipCallTo:           to,
+		RoomName:            roomName,
+		ParticipantIdentity: identity,
+		RingingTimeout:      durationpb.New(timeout),
+		MaxCallDuration:     durationpb.New(timeout + 10*time.Second),
+	})
+	if err != nil {
+		printSIPError(err)
+		return err
+	}
+
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	deadline := time.After(timeout)
+	lastStatus := ""
+	for {
+		p, err := roomClient.GetParticipant(ctx, &livekit.RoomParticipantIdentity{
+			Room:     roomName,
+			Identity: identity,
+		})
+		if err != nil {
+			if lastStatus == "" {
+				printSIPError(err)
+				return err
+			}
+			return fmt.Errorf("call ended before it was answered (last status: %s)", lastStatus)
+		}
+		if status := p.Attributes[livekit.AttrSIPCallStatus]; status != lastStatus {
+			fmt.Println("Call status:", status)
+			lastStatus = status
+			if status == "active" {
+				fmt.Println("Trunk OK: call was answered")
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline:
+			return fmt.Errorf("call was not answered within %s (last status: %s)", timeout, lastStatus)
+		case <-ticker.C:
+		}
+	}
+}
+
+func printSIPError(err error) {
+	var terr twirp.Error
+	if !errors.As(err, &terr) {
+		return
+	}
+	fmt.Printf("Error code: %s\n", terr.Code())
+	for k, v := range terr.MetaMap() {
+		fmt.Printf("%s: %s\n", k, v)
+	}
+}
+
 func transferSIPParticipant(ctx context.Context, cmd *cli.Command) error {
 	roomName, identity := participantInfoFromArgOrFlags(cmd)
 	to := cmd.String("to")
@@ -497,6 +1015,65 @@ func transferSIPParticipant(ctx context.Context, cmd *cli.Command) error {
 	return nil
 }
 
+type sipCall struct {
+	SIPCallID           string `json:"sip_call_id"`
+	ParticipantIdentity string `json:"participant_identity"`
+	RoomName            string `json:"room_name"`
+	PhoneNumber         string `json:"phone_number,omitempty"`
+	CallStatus          string `json:"call_status,omitempty"`
+}
+
+// listSIPParticipants finds active calls through the room service, as SIP
+// participants carry their call details in attributes.
+func listSIPParticipants(ctx context.Context, cmd *cli.Command) error {
+	roomNames := []string{cmd.String("room")}
+	allRooms := roomNames[0] == ""
+	if allRooms {
+		res, err := roomClient.ListRooms(ctx, &livekit.ListRoomsRequest{})
+		if err != nil {
+			return err
+		}
+		roomNames = roomNames[:0]
+		for _, rm := range res.Rooms {
+			roomNames = append(roomNames, rm.Name)
+		}
+	}
+
+	calls := make([]sipCall, 0)
+	for _, roomName := range roomNames {
+		res, err := roomClient.ListParticipants(ctx, &livekit.ListParticipantsRequest{Room: roomName})
+		var terr twirp.Error
+		if errors.As(err, &terr) && terr.Code() == twirp.NotFound && allRooms {
+			// the room closed since it was listed
+			continue
+		} else if err != nil {
+			return err
+		}
+		for _, p := range res.Participants {
+			if p.Kind != livekit.ParticipantInfo_SIP {
+				continue
+			}
+			calls = append(calls, sipCall{
+				SIPCallID:           p.Attributes[livekit.AttrSIPCallID],
+				ParticipantIdentity: p.Identity,
+				RoomName:            roomName,
+				PhoneNumber:         p.Attributes[livekit.AttrSIPPhoneNumber],
+				CallStatus:          p.Attributes[livekit.AttrSIPCallStatus],
+			})
+		}
+	}
+
+	if structuredOutput(cmd) {
+		printOutput(calls)
+		return nil
+	}
+	table := util.CreateTable().Headers("SIPCallID", "ParticipantIdentity", "RoomName", "PhoneNumber", "CallStatus")
+	for _, c := range calls {
+		table.Row(c.SIPCallID, c.ParticipantIdentity, c.RoomName, c.PhoneNumber, c.CallStatus)
+	}
+	return printTable(table)
+}
+
 func printSIPParticipantInfo(info *livekit.SIPParticipantInfo) {
 	fmt.Printf("SIPCallID: %v\n", info.SipCallId)
 	fmt.Printf("ParticipantID: %v\n", info.ParticipantId)
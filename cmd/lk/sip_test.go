@@ -0,0 +1,110 @@
+// Copyright 2024 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+func TestDialSIPParticipantCancel(t *testing.T) {
+	// server which never answers, like a hung outbound call
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	client := lksdk.NewSIPClient(srv.URL, "key", "secretsecretsecretsecretsecretsecret")
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := dialSIPParticipant(ctx, client.CreateSIPParticipant, &livekit.CreateSIPParticipantRequest{
+		SipTrunkId: "ST_test",
+		SipCallTo:  "+15550100",
+		RoomName:   "room",
+	}, sipDialTimeout)
+	require.ErrorIs(t, err, errSIPDialCancelled)
+	require.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestDialSIPParticipantTimeout(t *testing.T) {
+	dial := func(ctx context.Context, _ *livekit.CreateSIPParticipantRequest) (*livekit.SIPParticipantInfo, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	_, err := dialSIPParticipant(context.Background(), dial, &livekit.CreateSIPParticipantRequest{}, 10*time.Millisecond)
+	require.ErrorContains(t, err, "timed out")
+	require.NotErrorIs(t, err, errSIPDialCancelled)
+}
+
+func TestValidateSIPPin(t *testing.T) {
+	for _, pin := range []string{"1234", "000000", "123456789012"} {
+		if err := validateSIPPin(pin); err != nil {
+			t.Errorf("pin %q should be valid: %v", pin, err)
+		}
+	}
+	for _, pin := range []string{"", "123", "1234567890123", "12a4", "+1234"} {
+		if err := validateSIPPin(pin); err == nil {
+			t.Errorf("pin %q should be invalid", pin)
+		}
+	}
+}
+
+func TestValidateSIPNumbers(t *testing.T) {
+	if err := validateSIPNumbers([]string{"+15105550100", "+442071838750", "+12"}); err != nil {
+		t.Errorf("numbers should be valid: %v", err)
+	}
+	for _, number := range []string{"15105550100", "+0105550100", "+1 510 555 0100", "+1510555CALL", "+1234567890123456"} {
+		if err := validateSIPNumbers([]string{"+15105550100", number}); err == nil {
+			t.Errorf("number %q should be invalid", number)
+		}
+	}
+}
+
+func TestReadSIPDestinations(t *testing.T) {
+	dests, err := readSIPDestinations(strings.NewReader("call_to,identity,name\n+15105550100,alice,Alice\n+15105550101, bob\n"))
+	require.NoError(t, err)
+	require.Equal(t, []sipDestination{
+		{CallTo: "+15105550100", Identity: "alice", Name: "Alice"},
+		{CallTo: "+15105550101", Identity: "bob"},
+	}, dests)
+
+	_, err = readSIPDestinations(strings.NewReader("+15105550100\n"))
+	require.EqualError(t, err, "line 1: expected CALL_TO,IDENTITY[,NAME]", "identity is required")
+
+	_, err = readSIPDestinations(strings.NewReader("call_to,identity\n+15105550100,alice\n+15105550101\n"))
+	require.EqualError(t, err, "line 3: expected CALL_TO,IDENTITY[,NAME]", "line numbers count the header")
+
+	_, err = readSIPDestinations(strings.NewReader("call_to,identity\n\n+15105550100,alice\n\n+15105550101\n"))
+	require.EqualError(t, err, "line 5: expected CALL_TO,IDENTITY[,NAME]", "line numbers count blank lines")
+
+	_, err = readSIPDestinations(strings.NewReader("call_to,identity\n"))
+	require.Error(t, err, "at least one destination is required")
+}
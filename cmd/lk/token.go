@@ -16,14 +16,23 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
+	"os"
+	"reflect"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/huh"
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/skip2/go-qrcode"
 	"github.com/urfave/cli/v3"
+	"golang.org/x/term"
 
 	"github.com/livekit/livekit-cli/pkg/util"
 	"github.com/livekit/protocol/auth"
@@ -38,8 +47,46 @@ const (
 	usageEgress   = "Ability to interact with Egress services"
 	usageIngress  = "Ability to interact with Ingress services"
 	usageMetadata = "Ability to update their own name and metadata"
+	usagePreset   = "Grant a common bundle of permissions (requires --room and --identity), which other grant flags add to and --grant overrides:\n" +
+		"\tpublisher:  roomJoin, canPublish, canSubscribe, canPublishData\n" +
+		"\tsubscriber: roomJoin, canSubscribe, canPublishData (canPublish=false)\n" +
+		"\tviewer:     roomJoin, canSubscribe (canPublish=false, canPublishData=false)\n" +
+		"\tadmin:      roomJoin, roomAdmin, roomCreate, roomList, canPublish, canSubscribe, canPublishData, canUpdateOwnMetadata"
 )
 
+// tokenPresets are the grant bundles selectable with --preset. Keep usagePreset
+// in sync when changing them.
+var tokenPresets = map[string]func(grant *auth.VideoGrant){
+	"publisher": func(grant *auth.VideoGrant) {
+		grant.RoomJoin = true
+		grant.SetCanPublish(true)
+		grant.SetCanSubscribe(true)
+		grant.SetCanPublishData(true)
+	},
+	"subscriber": func(grant *auth.VideoGrant) {
+		grant.RoomJoin = true
+		grant.SetCanPublish(false)
+		grant.SetCanSubscribe(true)
+		grant.SetCanPublishData(true)
+	},
+	"viewer": func(grant *auth.VideoGrant) {
+		grant.RoomJoin = true
+		grant.SetCanPublish(false)
+		grant.SetCanSubscribe(true)
+		grant.SetCanPublishData(false)
+	},
+	"admin": func(grant *auth.VideoGrant) {
+		grant.RoomJoin = true
+		grant.RoomAdmin = true
+		grant.RoomCreate = true
+		grant.RoomList = true
+		grant.SetCanPublish(true)
+		grant.SetCanSubscribe(true)
+		grant.SetCanPublishData(true)
+		grant.SetCanUpdateOwnMetadata(true)
+	},
+}
+
 var (
 	TokenCommands = []*cli.Command{
 		{
@@ -52,6 +99,10 @@ var (
 					Usage:  "Creates an access token",
 					Action: createToken,
 					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "preset",
+							Usage: "`PRESET` of publisher, subscriber, viewer or admin. " + usagePreset,
+						},
 						&cli.BoolFlag{
 							Name:  "create",
 							Usage: usageCreate,
@@ -84,34 +135,63 @@ var (
 							Name:  "allow-source",
 							Usage: "Restrict publishing to only `SOURCE` types (e.g. --allow-source camera,microphone), defaults to all",
 						},
-						&cli.StringFlag{
+						defaultable(&cli.StringFlag{
 							Name:    "identity",
 							Aliases: []string{"i"},
 							Usage:   "Unique `ID` of the participant, used with --join",
-						},
+						}),
 						&cli.StringFlag{
 							Name:    "name",
 							Aliases: []string{"n"},
 							Usage:   "`NAME` of the participant, used with --join. defaults to identity",
 						},
-						&cli.StringFlag{
+						defaultable(&cli.StringFlag{
 							Name:    "room",
 							Aliases: []string{"r"},
 							Usage:   "`NAME` of the room to join",
-						},
+						}),
 						&cli.StringFlag{
 							Name:  "metadata",
 							Usage: "`JSON` metadata to encode in the token, will be passed to participant",
 						},
 						&cli.StringFlag{
-							Name:  "valid-for",
-							Usage: "`TIME` that the token is valid for, e.g. \"5m\", \"1h10m\" (s: seconds, m: minutes, h: hours)",
-							Value: "5m",
+							Name:    "valid-for",
+							Aliases: []string{"ttl"},
+							Usage:   "`TIME` that the token is valid for, e.g. \"5m\", \"1h10m\" (s: seconds, m: minutes, h: hours)",
+							Value:   "5m",
+						},
+						&cli.StringFlag{
+							Name:  "not-before",
+							Usage: "`TIME` the token becomes valid, as an RFC3339 timestamp or a duration from now, e.g. \"30m\". Expiry is still measured from now",
 						},
 						&cli.StringFlag{
 							Name:  "grant",
 							Usage: "Additional `VIDEO_GRANT` fields. It'll be merged with other arguments (JSON formatted)",
 						},
+						&cli.BoolFlag{
+							Name:  "qr",
+							Usage: "Print a QR code of the join URL (or the token when not joining) to the terminal",
+						},
+						&cli.StringFlag{
+							Name:      "qr-out",
+							Usage:     "Write a QR code of the join URL (or the token when not joining) as PNG to `FILE`",
+							TakesFile: true,
+						},
+					},
+				},
+				{
+					Name:      "verify",
+					Usage:     "Decode an access token and check its signature and expiry",
+					ArgsUsage: "TOKEN",
+					Description: "Prints the claims carried by TOKEN and verifies it against the project's API secret,\n" +
+						"exiting with status 1 if the signature is invalid or the token has expired.",
+					Action: verifyToken,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "token",
+							Usage: "Access `TOKEN` to verify, instead of passing it as an argument",
+						},
+						jsonFlag,
 					},
 				},
 			},
@@ -160,21 +240,21 @@ var (
 					Name:  "allow-source",
 					Usage: "Allow one or more `SOURCE`s to be published (i.e. --allow-source camera,microphone). if left blank, all sources are allowed",
 				},
-				&cli.StringFlag{
+				defaultable(&cli.StringFlag{
 					Name:    "identity",
 					Aliases: []string{"i"},
 					Usage:   "Unique `ID` of the participant, used with --join",
-				},
+				}),
 				&cli.StringFlag{
 					Name:    "name",
 					Aliases: []string{"n"},
 					Usage:   "`NAME` of the participant, used with --join. defaults to identity",
 				},
-				&cli.StringFlag{
+				defaultable(&cli.StringFlag{
 					Name:    "room",
 					Aliases: []string{"r"},
 					Usage:   "`NAME` of the room to join",
-				},
+				}),
 				&cli.StringFlag{
 					Name:  "room-configuration",
 					Usage: "name of the room configuration to use when creating a room",
@@ -209,6 +289,20 @@ func createToken(ctx context.Context, c *cli.Command) error {
 		Room: room,
 	}
 	hasPerms := false
+	if preset := c.String("preset"); preset != "" {
+		apply, ok := tokenPresets[preset]
+		if !ok {
+			return fmt.Errorf("invalid preset %q, expected one of publisher, subscriber, viewer or admin", preset)
+		}
+		if p == "" {
+			return errors.New("participant identity is required")
+		}
+		if room == "" {
+			return errors.New("room is required")
+		}
+		apply(grant)
+		hasPerms = true
+	}
 	if c.Bool("create") {
 		grant.RoomCreate = true
 		hasPerms = true
@@ -340,16 +434,36 @@ func createToken(ctx context.Context, c *cli.Command) error {
 		name = p
 	}
 	at.SetName(name)
+	now := time.Now()
+	notBefore, expiry := now, now.Add(5*time.Minute)
 	if validFor != "" {
 		if dur, err := time.ParseDuration(validFor); err == nil {
 			fmt.Println("valid for (mins): ", int(dur/time.Minute))
 			at.SetValidFor(dur)
+			expiry = now.Add(dur)
 		} else {
 			return err
 		}
 	}
+	if value := c.String("not-before"); value != "" {
+		if notBefore, err = parseNotBefore(value, now); err != nil {
+			return err
+		}
+		if !notBefore.Before(expiry) {
+			return fmt.Errorf("--not-before (%s) must be before the token expires (%s), increase --ttl",
+				notBefore.Format(time.RFC3339), expiry.Format(time.RFC3339))
+		}
+	}
+	if c.Bool("verbose") {
+		fmt.Printf("Token valid from %s until %s\n", notBefore.Format(time.RFC3339), expiry.Format(time.RFC3339))
+	}
 
-	token, err := at.ToJWT()
+	var token string
+	if c.IsSet("not-before") {
+		token, err = signTokenWindow(pc.APIKey, pc.APISecret, at.GetGrants(), notBefore, expiry)
+	} else {
+		token, err = at.ToJWT()
+	}
 	if err != nil {
 		return err
 	}
@@ -358,6 +472,78 @@ func createToken(ctx context.Context, c *cli.Command) error {
 	util.PrintJSON(grant)
 	fmt.Println()
 	fmt.Println("Access token:", token)
+
+	if c.Bool("qr") || c.IsSet("qr-out") {
+		content := token
+		if grant.RoomJoin && pc.URL != "" {
+			content = meetURL(pc.URL, token)
+		}
+		if err = printQRCode(content, c.Bool("qr"), c.String("qr-out")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseNotBefore accepts either an RFC3339 timestamp or a duration relative to now.
+func parseNotBefore(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --not-before %q, expected an RFC3339 timestamp or a duration such as \"30m\"", value)
+}
+
+// signTokenWindow signs grants with an explicit validity window. AccessToken.ToJWT
+// always sets nbf to the current time, so delayed tokens are signed here instead.
+func signTokenWindow(apiKey, apiSecret string, grants *auth.ClaimGrants, notBefore, expiry time.Time) (string, error) {
+	sig, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.HS256, Key: []byte(apiSecret)},
+		(&jose.SignerOptions{}).WithType("JWT"))
+	if err != nil {
+		return "", err
+	}
+	cl := jwt.Claims{
+		Issuer:    apiKey,
+		NotBefore: jwt.NewNumericDate(notBefore),
+		Expiry:    jwt.NewNumericDate(expiry),
+		Subject:   grants.Identity,
+	}
+	return jwt.Signed(sig).Claims(cl).Claims(grants).CompactSerialize()
+}
+
+func meetURL(serverURL, token string) string {
+	params := url.Values{}
+	params.Set("liveKitUrl", serverURL)
+	params.Set("token", token)
+	return "https://meet.livekit.io/custom?" + params.Encode()
+}
+
+// printQRCode renders content to the terminal and/or a PNG file. Terminal output
+// is skipped when stdout is not a TTY or NO_COLOR is set, as the block characters
+// will not scan reliably; outFile must be used instead.
+func printQRCode(content string, toTerminal bool, outFile string) error {
+	qr, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return err
+	}
+	if outFile != "" {
+		if err = qr.WriteFile(512, outFile); err != nil {
+			return err
+		}
+		fmt.Println("QR code written to", outFile)
+	}
+	if toTerminal {
+		if !term.IsTerminal(int(os.Stdout.Fd())) || os.Getenv("NO_COLOR") != "" {
+			if outFile == "" {
+				return errors.New("cannot render QR code to this terminal, use --qr-out to write a PNG instead")
+			}
+			return nil
+		}
+		fmt.Println()
+		fmt.Print(qr.ToSmallString(false))
+	}
 	return nil
 }
 
@@ -371,3 +557,148 @@ func accessToken(apiKey, apiSecret string, grant *auth.VideoGrant, identity stri
 		SetIdentity(identity)
 	return at
 }
+
+type tokenClaims struct {
+	auth.ClaimGrants
+	Issuer    string `json:"iss"`
+	Subject   string `json:"sub"`
+	NotBefore int64  `json:"nbf"`
+	Expiry    int64  `json:"exp"`
+}
+
+type tokenVerification struct {
+	Valid     bool              `json:"valid"`
+	Error     string            `json:"error,omitempty"`
+	APIKey    string            `json:"api_key"`
+	Identity  string            `json:"identity"`
+	NotBefore *time.Time        `json:"not_before,omitempty"`
+	ExpiresAt *time.Time        `json:"expires_at,omitempty"`
+	Grants    *auth.ClaimGrants `json:"grants"`
+}
+
+// decodeTokenClaims reads the claims of a JWT without verifying it, so that
+// they can be shown even when verification fails.
+func decodeTokenClaims(token string) (*tokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("token is not a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("could not decode token: %w", err)
+	}
+	claims := &tokenClaims{}
+	if err = json.Unmarshal(payload, claims); err != nil {
+		return nil, fmt.Errorf("could not decode token: %w", err)
+	}
+	return claims, nil
+}
+
+// newTokenVerification describes the claims of a token, leaving out the
+// validity bounds it doesn't set.
+func newTokenVerification(claims *tokenClaims) *tokenVerification {
+	v := &tokenVerification{
+		APIKey:   claims.Issuer,
+		Identity: claims.Subject,
+		Grants:   &claims.ClaimGrants,
+	}
+	if claims.NotBefore != 0 {
+		t := time.Unix(claims.NotBefore, 0)
+		v.NotBefore = &t
+	}
+	if claims.Expiry != 0 {
+		t := time.Unix(claims.Expiry, 0)
+		v.ExpiresAt = &t
+	}
+	return v
+}
+
+func verifyToken(ctx context.Context, c *cli.Command) error {
+	token := c.String("token")
+	if token == "" {
+		token = c.Args().First()
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return errors.New("token is required")
+	}
+
+	claims, err := decodeTokenClaims(token)
+	if err != nil {
+		return err
+	}
+	result := newTokenVerification(claims)
+
+	pc, err := loadProjectDetails(c, ignoreURL)
+	if err != nil {
+		return err
+	}
+	if claims.Issuer != pc.APIKey {
+		err = fmt.Errorf("token was issued by API key %s, not the project's key %s", claims.Issuer, pc.APIKey)
+	} else if verifier, parseErr := auth.ParseAPIToken(token); parseErr != nil {
+		err = parseErr
+	} else if _, err = verifier.Verify(pc.APISecret); err == nil {
+		// Verify allows a minute of clock skew, report the exact window instead
+		now := time.Now()
+		if result.ExpiresAt != nil && now.After(*result.ExpiresAt) {
+			err = fmt.Errorf("token expired at %s", result.ExpiresAt.Local().Format(time.RFC3339))
+		} else if result.NotBefore != nil && now.Before(*result.NotBefore) {
+			err = fmt.Errorf("token is not valid until %s", result.NotBefore.Local().Format(time.RFC3339))
+		}
+	}
+	result.Valid = err == nil
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	if structuredOutput(c) {
+		printOutput(result)
+	} else if err = printTokenVerification(result); err != nil {
+		return err
+	}
+	if !result.Valid {
+		return cli.Exit("", 1)
+	}
+	return nil
+}
+
+func printTokenVerification(v *tokenVerification) error {
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Local().Format(time.RFC3339)
+	}
+	formatJSON := func(obj any) string {
+		if reflect.ValueOf(obj).IsNil() {
+			return ""
+		}
+		b, _ := json.Marshal(obj)
+		return string(b)
+	}
+
+	table := util.CreateTable().Headers("Claim", "Value")
+	table.Row("Identity", v.Identity)
+	table.Row("Name", v.Grants.Name)
+	table.Row("Kind", v.Grants.Kind)
+	table.Row("API Key", v.APIKey)
+	table.Row("Not Before", formatTime(v.NotBefore))
+	table.Row("Expires At", formatTime(v.ExpiresAt))
+	table.Row("Video Grant", formatJSON(v.Grants.Video))
+	table.Row("SIP Grant", formatJSON(v.Grants.SIP))
+	table.Row("Room Preset", v.Grants.RoomPreset)
+	table.Row("Metadata", v.Grants.Metadata)
+	if len(v.Grants.Attributes) > 0 {
+		table.Row("Attributes", formatJSON(v.Grants.Attributes))
+	}
+	if err := printTable(table); err != nil {
+		return err
+	}
+
+	if v.Valid {
+		fmt.Println("Token is valid")
+	} else {
+		fmt.Println("Token is invalid:", v.Error)
+	}
+	return nil
+}
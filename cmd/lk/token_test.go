@@ -0,0 +1,53 @@
+// Copyright 2024 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func unsignedToken(claims string) string {
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + enc.EncodeToString([]byte(claims)) + ".sig"
+}
+
+func TestTokenVerificationJSON(t *testing.T) {
+	claims, err := decodeTokenClaims(unsignedToken(`{"iss":"APIkey","sub":"alice"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := json.Marshal(newTokenVerification(claims))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(out), "not_before") || strings.Contains(string(out), "expires_at") {
+		t.Errorf("a token without nbf or exp should leave them out, got %s", out)
+	}
+
+	claims, err = decodeTokenClaims(unsignedToken(`{"iss":"APIkey","sub":"alice","exp":1700000000}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err = json.Marshal(newTokenVerification(claims))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(out), "not_before") || !strings.Contains(string(out), `"expires_at":"`) {
+		t.Errorf("expected only expires_at to be set, got %s", out)
+	}
+}
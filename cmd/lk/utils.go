@@ -15,10 +15,16 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/twitchtv/twirp"
 	"github.com/urfave/cli/v3"
@@ -29,23 +35,40 @@ import (
 )
 
 var (
-	roomFlag = &cli.StringFlag{
+	roomFlag = defaultable(&cli.StringFlag{
 		Name:     "room",
 		Usage:    "`NAME` of the room",
 		Required: true,
-	}
-	identityFlag = &cli.StringFlag{
+	})
+	identityFlag = defaultable(&cli.StringFlag{
 		Name:     "identity",
 		Usage:    "`ID` of participant",
 		Required: true,
-	}
+	})
 	jsonFlag = &cli.BoolFlag{
-		Name:    "json",
-		Aliases: []string{"j"},
-		Usage:   "Output as JSON",
+		Name:        "json",
+		Aliases:     []string{"j"},
+		Usage:       "Output as JSON (deprecated, use --output json)",
+		Destination: &printJSON,
 	}
-	printCurl   bool
-	globalFlags = []cli.Flag{
+	printJSON    bool
+	printCurl    bool
+	verbose      bool
+	outputFormat = outputTable
+	globalFlags  = []cli.Flag{
+		&cli.StringFlag{
+			Name:        "output",
+			Aliases:     []string{"o"},
+			Usage:       "Output `FORMAT` of commands that print results: " + strings.Join(outputFormats, ", "),
+			Value:       outputTable,
+			Destination: &outputFormat,
+			Validator: func(format string) error {
+				if !slices.Contains(outputFormats, format) {
+					return fmt.Errorf("expected one of %s", strings.Join(outputFormats, ", "))
+				}
+				return nil
+			},
+		},
 		&cli.StringFlag{
 			Name:    "url",
 			Usage:   "`URL` to LiveKit instance",
@@ -63,8 +86,22 @@ var (
 			Sources: cli.EnvVars("LIVEKIT_API_SECRET"),
 		},
 		&cli.StringFlag{
+			Name:  "region-url",
+			Usage: "Regional endpoint `URL` to connect to, overriding the project's URL and configured region URL",
+		},
+		&cli.StringSliceFlag{
+			Name:        "columns",
+			Usage:       "Only show the given table `COLUMNS`, e.g. --columns name,status",
+			Destination: &util.TableColumns,
+		},
+		&cli.BoolFlag{
+			Name:        "no-headers",
+			Usage:       "Omit the header row from table output",
+			Destination: &util.TableNoHeaders,
+		},
+		&cli.StringSliceFlag{
 			Name:  "project",
-			Usage: "`NAME` of a configured project",
+			Usage: "`NAME` of a configured project. List commands accept it multiple times to query several projects",
 		},
 		&cli.BoolFlag{
 			Name:        "curl",
@@ -73,31 +110,105 @@ var (
 			Required:    false,
 		},
 		&cli.BoolFlag{
-			Name:     "verbose",
-			Required: false,
+			Name:        "verbose",
+			Usage:       "Print additional details, including the duration of each API call",
+			Destination: &verbose,
+			Required:    false,
+		},
+		&cli.BoolFlag{
+			Name:  "include-hidden",
+			Usage: "List hidden and deprecated commands in help and shell completion, and hidden app templates",
 		},
 	}
 )
 
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+var outputFormats = []string{outputTable, outputJSON, outputYAML}
+
+// structuredOutput reports whether results should be printed with printOutput
+// rather than as a table, because of --output or the deprecated --json.
+func structuredOutput(cmd *cli.Command) bool {
+	return cmd.Bool("json") || outputFormat == outputJSON || outputFormat == outputYAML
+}
+
+// printOutput prints obj as YAML when --output yaml is given, JSON otherwise.
+func printOutput(obj any) {
+	if outputFormat == outputYAML && !printJSON {
+		util.PrintYAML(obj)
+	} else {
+		util.PrintJSON(obj)
+	}
+}
+
+// printTable prints a table built with util.CreateTable, failing when
+// --columns names a column it doesn't have.
+func printTable(table *util.Table) error {
+	out, err := table.Render()
+	if err != nil {
+		return err
+	}
+	fmt.Println(out)
+	return nil
+}
+
+// printOutputRedacted is printOutput for results that may carry credentials,
+// masking them as util.PrintJSONRedacted does.
+func printOutputRedacted(obj any) {
+	printOutput(json.RawMessage(util.RedactJSON(obj)))
+}
+
+func templateFlag[T any]() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name: "template",
+		Usage: "Render each result with Go `TEMPLATE`, e.g. '{{.Name}}'. Available fields: " +
+			strings.Join(util.FieldNames[T](), ", "),
+	}
+}
+
 func optional[T any, C any, VC cli.ValueCreator[T, C]](flag *cli.FlagBase[T, C, VC]) *cli.FlagBase[T, C, VC] {
 	newFlag := *flag
 	newFlag.Required = false
+	if defaultableFlags[flag] {
+		defaultableFlags[&newFlag] = true
+	}
 	return &newFlag
 }
 
 func hidden[T any, C any, VC cli.ValueCreator[T, C]](flag *cli.FlagBase[T, C, VC]) *cli.FlagBase[T, C, VC] {
 	newFlag := *flag
 	newFlag.Hidden = true
+	if defaultableFlags[flag] {
+		defaultableFlags[&newFlag] = true
+	}
 	return &newFlag
 }
 
+// flags naming the room or participant to act on, which fall back to the
+// configured defaults when omitted, see withConfigDefaults
+var defaultableFlags = make(map[cli.Flag]bool)
+
+// defaultable marks a string flag naming the room or participant to act on,
+// so that it falls back to the configured default when omitted.
+func defaultable(flag *cli.StringFlag) *cli.StringFlag {
+	defaultableFlags[flag] = true
+	return flag
+}
+
 func withDefaultClientOpts(c *config.ProjectConfig) []twirp.ClientOption {
 	var (
 		opts []twirp.ClientOption
 		ics  []twirp.Interceptor
 	)
 	if printCurl {
-		ics = append(ics, interceptors.NewCurlPrinter(os.Stdout, c.URL))
+		ics = append(ics, interceptors.NewCurlPrinter(os.Stdout, c.APIURL()))
+	}
+	if verbose {
+		ics = append(ics, newTimingPrinter(os.Stderr))
 	}
 	if len(ics) != 0 {
 		opts = append(opts, twirp.WithClientInterceptors(ics...))
@@ -105,6 +216,20 @@ func withDefaultClientOpts(c *config.ProjectConfig) []twirp.ClientOption {
 	return opts
 }
 
+// newTimingPrinter reports the wall-clock duration of every API call, which helps
+// tell client-side latency apart from time spent on the server.
+func newTimingPrinter(w io.Writer) twirp.Interceptor {
+	return func(next twirp.Method) twirp.Method {
+		return func(ctx context.Context, req any) (any, error) {
+			start := time.Now()
+			resp, err := next(ctx, req)
+			method, _ := twirp.MethodName(ctx)
+			fmt.Fprintf(w, "%s took %s\n", method, time.Since(start).Round(time.Millisecond))
+			return resp, err
+		}
+	}
+}
+
 func extractArg(c *cli.Command) (string, error) {
 	if !c.Args().Present() {
 		return "", errors.New("no argument provided")
@@ -121,7 +246,8 @@ func extractArgs(c *cli.Command) ([]string, error) {
 
 func extractFlagOrArg(c *cli.Command, flag string) (string, error) {
 	value := c.String(flag)
-	if value == "" {
+	// an argument takes precedence over a value from config defaults
+	if value == "" || (defaultedFlags[flag] && c.Args().Present()) {
 		argValue := c.Args().First()
 		if argValue == "" {
 			return "", fmt.Errorf("no option or argument found for \"--%s\"", flag)
@@ -144,7 +270,27 @@ var ignoreURL = func(p *loadParams) {
 // attempt to load connection config, it'll prioritize
 // 1. command line flags (or env var)
 // 2. default project config
+// then apply --region-url, which API clients pick up through APIURL. The
+// project's own URL is left as is, e.g. for generated app environments.
 func loadProjectDetails(c *cli.Command, opts ...loadOption) (*config.ProjectConfig, error) {
+	pc, err := resolveProjectDetails(c, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if c.IsSet("region-url") {
+		regional := *pc
+		if regional.RegionURL = c.String("region-url"); !urlRegex.MatchString(regional.RegionURL) {
+			return nil, fmt.Errorf("invalid --region-url %q", regional.RegionURL)
+		}
+		pc = &regional
+	}
+	if pc.RegionURL != "" && c.Bool("verbose") {
+		fmt.Println("Using regional URL", pc.RegionURL)
+	}
+	return pc, nil
+}
+
+func resolveProjectDetails(c *cli.Command, opts ...loadOption) (*config.ProjectConfig, error) {
 	p := loadParams{requireURL: true}
 	for _, opt := range opts {
 		opt(&p)
@@ -161,12 +307,18 @@ func loadProjectDetails(c *cli.Command, opts ...loadOption) (*config.ProjectConf
 	}
 
 	// if explicit project is defined, then use it
-	if c.String("project") != "" {
-		pc, err := config.LoadProject(c.String("project"))
+	if projectOverride != nil {
+		logDetails(c, projectOverride)
+		return projectOverride, nil
+	}
+	if projects := c.StringSlice("project"); len(projects) > 1 {
+		return nil, errors.New("--project can only be given more than once for list commands")
+	} else if len(projects) == 1 {
+		pc, err := config.LoadProject(projects[0])
 		if err != nil {
 			return nil, err
 		}
-		fmt.Println("Using project [" + util.Theme.Focused.Title.Render(c.String("project")) + "]")
+		fmt.Println("Using project [" + util.Theme.Focused.Title.Render(projects[0]) + "]")
 		logDetails(c, pc)
 		return pc, nil
 	}
@@ -223,3 +375,53 @@ func loadProjectDetails(c *cli.Command, opts ...loadOption) (*config.ProjectConf
 	// cannot happen
 	return pc, nil
 }
+
+// collectIDs gathers IDs given with the named flag and as arguments. An ID of
+// "-" reads further newline-delimited IDs from stdin, so that the output of
+// list commands can be piped in.
+func collectIDs(cmd *cli.Command, flag string) ([]string, error) {
+	var ids []string
+	readStdin := false
+	for _, id := range append(cmd.StringSlice(flag), cmd.Args().Slice()...) {
+		if id == "-" {
+			readStdin = true
+			continue
+		}
+		ids = append(ids, id)
+	}
+	if readStdin {
+		stdinIDs, err := readIDs(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, stdinIDs...)
+	}
+	return ids, nil
+}
+
+// readIDs reads one ID per line, skipping blank lines
+func readIDs(r io.Reader) ([]string, error) {
+	var ids []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if id := strings.TrimSpace(scanner.Text()); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids, scanner.Err()
+}
+
+// parseKeyValuePairs parses KEY=VALUE entries into a map. Values may contain
+// '=', but every entry needs a non-empty key.
+func parseKeyValuePairs(pairs []string) (map[string]string, error) {
+	result := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid entry %q, expected KEY=VALUE", pair)
+		}
+		result[key] = value
+	}
+	return result, nil
+}
@@ -15,9 +15,17 @@
 package main
 
 import (
+	"context"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/urfave/cli/v3"
+
+	"github.com/livekit/livekit-cli/pkg/config"
 )
 
 func TestOptionalFlag(t *testing.T) {
@@ -55,3 +63,87 @@ func TestHiddenFlag(t *testing.T) {
 		t.Error("hidden should return a new flag with Hidden set to true")
 	}
 }
+
+func TestReadIDs(t *testing.T) {
+	ids, err := readIDs(strings.NewReader("EG_1\n\n  EG_2  \r\nEG_3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal([]string{"EG_1", "EG_2", "EG_3"}, ids) {
+		t.Errorf("readIDs should return trimmed, non-empty lines, got %v", ids)
+	}
+}
+
+func TestParseKeyValuePairs(t *testing.T) {
+	pairs, err := parseKeyValuePairs([]string{"role=moderator", "query=a=b", "empty="})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"role": "moderator", "query": "a=b", "empty": ""}
+	if !maps.Equal(expected, pairs) {
+		t.Errorf("expected %v, got %v", expected, pairs)
+	}
+
+	for _, malformed := range []string{"role", "=value", " =value"} {
+		if _, err = parseKeyValuePairs([]string{malformed}); err == nil {
+			t.Errorf("expected an error for %q", malformed)
+		}
+	}
+}
+
+func TestLoadProjectDetailsRegionURL(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, env := range []string{"LIVEKIT_URL", "LIVEKIT_API_KEY", "LIVEKIT_API_SECRET"} {
+		// restored by Setenv when the test ends
+		t.Setenv(env, "")
+		os.Unsetenv(env)
+	}
+	if err := os.MkdirAll(filepath.Join(home, ".livekit"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	conf := `default_project: p1
+projects:
+  - name: p1
+    url: wss://p1.example.com
+    api_key: APIkey
+    api_secret: secret
+    region_url: wss://p1-eu.example.com
+`
+	if err := os.WriteFile(filepath.Join(home, ".livekit", "cli-config.yaml"), []byte(conf), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	load := func(args ...string) *config.ProjectConfig {
+		var pc *config.ProjectConfig
+		cmd := &cli.Command{
+			Name:  "lk",
+			Flags: globalFlags,
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				var err error
+				pc, err = loadProjectDetails(cmd)
+				return err
+			},
+		}
+		if err := cmd.Run(context.Background(), append([]string{"lk"}, args...)); err != nil {
+			t.Fatal(err)
+		}
+		return pc
+	}
+
+	pc := load()
+	if pc.APIURL() != "wss://p1-eu.example.com" {
+		t.Errorf("expected API calls to use the configured region URL, got %s", pc.APIURL())
+	}
+	if pc.URL != "wss://p1.example.com" {
+		t.Errorf("the project URL should be unchanged, got %s", pc.URL)
+	}
+
+	pc = load("--region-url", "wss://p1-us.example.com")
+	if pc.APIURL() != "wss://p1-us.example.com" {
+		t.Errorf("expected --region-url to take precedence, got %s", pc.APIURL())
+	}
+	if stored, _ := config.LoadProject("p1"); stored.RegionURL != "wss://p1-eu.example.com" {
+		t.Errorf("--region-url should not change the stored project, got %s", stored.RegionURL)
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"os/exec"
 	"path"
 	"runtime"
+	"slices"
 	"strings"
 
 	"github.com/go-task/task/v3"
@@ -70,10 +71,11 @@ type Template struct {
 	URL       string            `yaml:"url" json:"url,omitempty"`
 	Docs      string            `yaml:"docs" json:"docs_url,omitempty"`
 	Image     string            `yaml:"image" json:"image_ref,omitempty"`
-	Tags      []string          `yaml:"tags" json:"tags,omitempty"`
+	Tags      []string          `yaml:"tags" json:"tags"`
 	Attrs     map[string]string `yaml:"attrs" json:"attrs,omitempty"`
 	Requires  []string          `yaml:"requires" json:"requires,omitempty"`
 	IsSandbox bool              `yaml:"is_sandbox" json:"is_sandbox,omitempty"`
+	IsHidden  bool              `yaml:"is_hidden" json:"is_hidden"`
 }
 
 type SandboxDetails struct {
@@ -92,9 +94,25 @@ func FetchTemplates(ctx context.Context) ([]Template, error) {
 	if err := yaml.NewDecoder(resp.Body).Decode(&templates); err != nil {
 		return nil, err
 	}
+	for i := range templates {
+		templates[i].Tags = normalizeTags(templates[i].Tags)
+	}
 	return templates, nil
 }
 
+// normalizeTags lowercases and trims tags, dropping empty and duplicate ones,
+// and never returns nil so that JSON consumers always see an array.
+func normalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(tag, "#")))
+		if tag != "" && !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
+
 func FetchSandboxDetails(ctx context.Context, sid, token, serverURL string) (*SandboxDetails, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", serverURL+SandboxTemplateEndpoint, nil)
 	req.Header = authutil.NewHeaderWithToken(token)
@@ -25,17 +25,36 @@ import (
 )
 
 type CLIConfig struct {
-	DefaultProject string          `yaml:"default_project"`
-	Projects       []ProjectConfig `yaml:"projects"`
+	DefaultProject   string          `yaml:"default_project"`
+	Projects         []ProjectConfig `yaml:"projects"`
+	DisableTelemetry bool            `yaml:"disable_telemetry,omitempty"`
+	Defaults         Defaults        `yaml:"defaults,omitempty"`
 	// absent from YAML
 	hasPersisted bool
 }
 
+// Defaults are used in place of --room and --identity when they are omitted
+type Defaults struct {
+	Room     string `yaml:"room,omitempty"`
+	Identity string `yaml:"identity,omitempty"`
+}
+
 type ProjectConfig struct {
 	Name      string `yaml:"name"`
 	URL       string `yaml:"url"`
 	APIKey    string `yaml:"api_key"`
 	APISecret string `yaml:"api_secret"`
+	// optional regional endpoint to connect to instead of URL
+	RegionURL string `yaml:"region_url,omitempty"`
+}
+
+// APIURL returns the URL API calls and connections should go to: the regional
+// URL when one is pinned, the project URL otherwise.
+func (p *ProjectConfig) APIURL() string {
+	if p.RegionURL != "" {
+		return p.RegionURL
+	}
+	return p.URL
 }
 
 func LoadDefaultProject() (*ProjectConfig, error) {
@@ -135,12 +154,40 @@ func (c *CLIConfig) RemoveProject(name string) error {
 	return nil
 }
 
+// TelemetryEnabled reports whether anonymous usage telemetry may be sent, and
+// must be checked before making any network call that isn't part of the
+// requested operation. The DO_NOT_TRACK environment variable takes precedence
+// over the config file.
+func (c *CLIConfig) TelemetryEnabled() bool {
+	if DoNotTrack() {
+		return false
+	}
+	return !c.DisableTelemetry
+}
+
+// DoNotTrack reports whether the DO_NOT_TRACK environment variable is set.
+func DoNotTrack() bool {
+	v, ok := os.LookupEnv("DO_NOT_TRACK")
+	if !ok {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "", "0", "false", "no":
+		return false
+	default:
+		return true
+	}
+}
+
 func (c *CLIConfig) PersistIfNeeded() error {
 	if len(c.Projects) == 0 && !c.hasPersisted {
 		// doesn't need to be persisted
 		return nil
 	}
+	return c.Persist()
+}
 
+func (c *CLIConfig) Persist() error {
 	configPath, err := getConfigLocation()
 	if err != nil {
 		return err
@@ -157,6 +204,7 @@ func (c *CLIConfig) PersistIfNeeded() error {
 	if err = os.WriteFile(configPath, data, 0600); err != nil {
 		return err
 	}
+	c.hasPersisted = true
 	fmt.Println("Saved CLI config to", configPath)
 	return nil
 }
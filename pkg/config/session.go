@@ -0,0 +1,102 @@
+// Copyright 2024 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SessionConfig holds short-lived state, such as cached cloud tokens, that is
+// kept separate from cli-config.yaml so it can be discarded at any time.
+type SessionConfig struct {
+	Tokens map[string]CachedToken `yaml:"tokens,omitempty"`
+}
+
+type CachedToken struct {
+	APIKey    string    `yaml:"api_key"`
+	Identity  string    `yaml:"identity"`
+	Token     string    `yaml:"token"`
+	ExpiresAt time.Time `yaml:"expires_at"`
+}
+
+// ValidFor reports whether the token was issued for the given key and
+// identity and remains valid for at least d.
+func (t CachedToken) ValidFor(apiKey, identity string, d time.Duration) bool {
+	return t.Token != "" &&
+		t.APIKey == apiKey &&
+		t.Identity == identity &&
+		time.Now().Add(d).Before(t.ExpiresAt)
+}
+
+// LoadSession loads session state from ~/.livekit/cli-session.yaml. A missing
+// or unreadable file yields an empty session.
+func LoadSession() *SessionConfig {
+	s := &SessionConfig{}
+	sessionPath, err := getSessionLocation()
+	if err != nil {
+		return s
+	}
+	content, err := os.ReadFile(sessionPath)
+	if err != nil {
+		return s
+	}
+	_ = yaml.Unmarshal(content, s)
+	return s
+}
+
+func (s *SessionConfig) Token(projectName string) (CachedToken, bool) {
+	t, ok := s.Tokens[projectName]
+	return t, ok
+}
+
+func (s *SessionConfig) SetToken(projectName string, t CachedToken) {
+	if s.Tokens == nil {
+		s.Tokens = make(map[string]CachedToken)
+	}
+	s.Tokens[projectName] = t
+}
+
+func (s *SessionConfig) RemoveToken(projectName string) {
+	delete(s.Tokens, projectName)
+}
+
+func (s *SessionConfig) Persist() error {
+	sessionPath, err := getSessionLocation()
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(path.Dir(sessionPath), 0700); err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(sessionPath, data, 0600)
+}
+
+func getSessionLocation() (string, error) {
+	dir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(dir, ".livekit", "cli-session.yaml"), nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2024 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCachedTokenValidFor(t *testing.T) {
+	token := CachedToken{
+		APIKey:    "APIkey",
+		Identity:  "hash",
+		Token:     "jwt",
+		ExpiresAt: time.Now().Add(30 * time.Minute),
+	}
+	if !token.ValidFor("APIkey", "hash", 5*time.Minute) {
+		t.Error("token should be reused well before expiry")
+	}
+	if token.ValidFor("APIkey", "hash", time.Hour) {
+		t.Error("token should be refreshed within the margin of its expiry")
+	}
+	if token.ValidFor("otherKey", "hash", 5*time.Minute) {
+		t.Error("token should not be reused for another API key")
+	}
+	if token.ValidFor("APIkey", "otherHash", 5*time.Minute) {
+		t.Error("token should not be reused once the secret changed")
+	}
+}
+
+func TestSessionPersist(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	s := LoadSession()
+	s.SetToken("p1", CachedToken{APIKey: "APIkey", Token: "jwt", ExpiresAt: time.Now().Add(time.Hour)})
+	if err := s.Persist(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, ok := LoadSession().Token("p1"); !ok || got.Token != "jwt" {
+		t.Errorf("expected the cached token to be reloaded, got %+v", got)
+	}
+	if _, ok := LoadSession().Token("p2"); ok {
+		t.Error("expected no token for another project")
+	}
+}
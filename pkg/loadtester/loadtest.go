@@ -19,6 +19,8 @@ import (
 	"fmt"
 	"math/rand"
 	"net/url"
+	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
@@ -47,10 +49,23 @@ type Params struct {
 	VideoResolution string
 	VideoCodec      string
 	Duration        time.Duration
+	// how long to run when Duration is 0, rather than until canceled. It must
+	// not be shorter than Duration when both are set; 0 means unlimited
+	MaxDuration time.Duration
 	// number of seconds to spin up per second
-	NumPerSecond     float64
-	Simulcast        bool
-	SimulateSpeakers bool
+	NumPerSecond float64
+	// number of testers to disconnect per second once Duration has elapsed;
+	// 0 disconnects all of them at once
+	RampDownPerSecond float64
+	Simulcast         bool
+	SimulateSpeakers  bool
+	// VP8 .ivf and Opus .ogg files to loop instead of synthetic media, spread
+	// evenly across video and audio publishers respectively
+	PublishFiles []string
+	// file to periodically write JSON stats snapshots to, one object per line
+	StatsOut string
+	// how often to write to StatsOut, defaults to 5s
+	StatsInterval time.Duration
 
 	TesterParams
 }
@@ -79,6 +94,9 @@ func (t *LoadTest) Run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	if t.Params.MaxDuration > 0 && t.Params.Duration > t.Params.MaxDuration {
+		return fmt.Errorf("duration %s exceeds max duration %s", t.Params.Duration, t.Params.MaxDuration)
+	}
 	if strings.HasSuffix(parsedUrl.Hostname(), ".livekit.cloud") {
 		if t.Params.VideoPublishers > 50 || t.Params.Subscribers > 50 || t.Params.AudioPublishers > 50 {
 			return errors.New("Unable to perform load test on LiveKit Cloud. Load testing is prohibited by our acceptable use policy: https://livekit.io/legal/acceptable-use-policy")
@@ -179,7 +197,7 @@ func (t *LoadTest) Run(ctx context.Context) error {
 			formatBitrate(s.bytes, s.elapsed),
 			formatBitrate(s.bytes/int64(len(summaries)), s.elapsed),
 		)
-		summaryTable.Row("Total", fmt.Sprintf("%d/%d", s.tracks, s.expected), sBitrate, sDropped, string(s.errCount))
+		summaryTable.Row("Total", fmt.Sprintf("%d/%d", s.tracks, s.expected), sBitrate, sDropped, strconv.FormatInt(s.errCount, 10))
 	}
 	fmt.Println("\nSubscriber summaries:")
 	fmt.Println(summaryTable)
@@ -294,6 +312,11 @@ func (t *LoadTest) run(ctx context.Context, params Params) (map[string]*testerSt
 	fmt.Printf("Starting load test with %s, room: %s\n",
 		strings.Join(participantStrings, ", "), params.Room)
 
+	videoFiles, audioFiles, err := splitPublishFiles(params.PublishFiles)
+	if err != nil {
+		return nil, err
+	}
+
 	var publishers, testers []*LoadTester
 	group, _ := errgroup.WithContext(ctx)
 	errs := syncmap.Map{}
@@ -302,6 +325,25 @@ func (t *LoadTest) run(ctx context.Context, params Params) (map[string]*testerSt
 		maxPublishers = params.AudioPublishers
 	}
 
+	var statsOut *statsWriter
+	if params.StatsOut != "" {
+		var err error
+		if statsOut, err = newStatsWriter(params.StatsOut); err != nil {
+			return nil, err
+		}
+		interval := params.StatsInterval
+		if interval <= 0 {
+			interval = 5 * time.Second
+		}
+		statsOut.start(interval)
+		defer func() {
+			// flush on early exits; the normal path closes it after disconnecting
+			if statsOut != nil {
+				_ = statsOut.close(nil)
+			}
+		}()
+	}
+
 	// throttle pace of join events
 	limiter := rate.NewLimiter(rate.Limit(params.NumPerSecond), 1)
 	for i := 0; i < maxPublishers+params.Subscribers; i++ {
@@ -322,6 +364,9 @@ func (t *LoadTest) run(ctx context.Context, params Params) (map[string]*testerSt
 
 		tester := NewLoadTester(testerParams)
 		testers = append(testers, tester)
+		if statsOut != nil {
+			statsOut.add(tester)
+		}
 		if isVideoPublisher || isAudioPublisher {
 			publishers = append(publishers, tester)
 		}
@@ -334,7 +379,13 @@ func (t *LoadTest) run(ctx context.Context, params Params) (map[string]*testerSt
 			}
 
 			if isAudioPublisher {
-				audio, err := tester.PublishAudioTrack("audio")
+				var audio string
+				var err error
+				if len(audioFiles) > 0 {
+					audio, err = tester.PublishFileTrack("audio", audioFiles[testerParams.Sequence%len(audioFiles)])
+				} else {
+					audio, err = tester.PublishAudioTrack("audio")
+				}
 				if err != nil {
 					errs.Store(testerParams.name, err)
 					return nil
@@ -346,7 +397,9 @@ func (t *LoadTest) run(ctx context.Context, params Params) (map[string]*testerSt
 			if isVideoPublisher {
 				var video string
 				var err error
-				if params.Simulcast {
+				if len(videoFiles) > 0 {
+					video, err = tester.PublishFileTrack("video", videoFiles[testerParams.Sequence%len(videoFiles)])
+				} else if params.Simulcast {
 					video, err = tester.PublishSimulcastTrack("video-simulcast", params.VideoResolution, params.VideoCodec)
 				} else {
 					video, err = tester.PublishVideoTrack("video", params.VideoResolution, params.VideoCodec)
@@ -383,25 +436,43 @@ func (t *LoadTest) run(ctx context.Context, params Params) (map[string]*testerSt
 	}
 
 	duration := params.Duration
+	if duration == 0 {
+		duration = params.MaxDuration
+	}
 	if duration == 0 {
 		// a really long time
 		duration = 1000 * time.Hour
 	}
 	fmt.Printf("Finished connecting to room, waiting %s\n", duration.String())
 
+	rampDown := false
 	select {
 	case <-ctx.Done():
 		// canceled
 	case <-time.After(duration):
 		// finished
+		rampDown = params.RampDownPerSecond > 0
 	}
 
 	if speakerSim != nil {
 		speakerSim.Stop()
 	}
 
+	var rampDownLimiter *rate.Limiter
+	if rampDown {
+		fmt.Printf("Ramping down, disconnecting %.1f testers per second\n", params.RampDownPerSecond)
+		rampDownLimiter = rate.NewLimiter(rate.Limit(params.RampDownPerSecond), 1)
+	}
+
+	// disconnect in reverse join order, so subscribers leave before the
+	// publishers they are subscribed to
 	stats := make(map[string]*testerStats)
-	for _, t := range testers {
+	for i := len(testers) - 1; i >= 0; i-- {
+		t := testers[i]
+		if rampDownLimiter != nil && rampDownLimiter.Wait(ctx) != nil {
+			// canceled during ramp-down, disconnect the rest at once
+			rampDownLimiter = nil
+		}
 		t.Stop()
 		stats[t.params.name] = t.getStats()
 		if e, _ := errs.Load(t.params.name); e != nil {
@@ -409,5 +480,34 @@ func (t *LoadTest) run(ctx context.Context, params Params) (map[string]*testerSt
 		}
 	}
 
+	if statsOut != nil {
+		testerErrs := make(map[string]error)
+		for name, s := range stats {
+			testerErrs[name] = s.err
+		}
+		if err := statsOut.close(testerErrs); err != nil {
+			fmt.Printf("failed to write stats to %s: %v\n", params.StatsOut, err)
+		}
+		statsOut = nil
+	}
+
 	return stats, nil
 }
+
+// splitPublishFiles sorts files into video (.ivf) and audio (.ogg) files.
+func splitPublishFiles(files []string) (video, audio []string, err error) {
+	for _, f := range files {
+		if _, err = os.Stat(f); err != nil {
+			return nil, nil, err
+		}
+		switch strings.ToLower(filepath.Ext(f)) {
+		case ".ivf":
+			video = append(video, f)
+		case ".ogg":
+			audio = append(audio, f)
+		default:
+			return nil, nil, fmt.Errorf("unsupported file %s, expected .ivf or .ogg", f)
+		}
+	}
+	return video, audio, nil
+}
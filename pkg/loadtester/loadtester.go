@@ -15,7 +15,11 @@
 package loadtester
 
 import (
+	"errors"
 	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -25,6 +29,7 @@ import (
 	"go.uber.org/atomic"
 
 	provider2 "github.com/livekit/livekit-cli/pkg/provider"
+	"github.com/livekit/livekit-cli/pkg/util"
 	"github.com/livekit/protocol/livekit"
 	lksdk "github.com/livekit/server-sdk-go/v2"
 	"github.com/livekit/server-sdk-go/v2/pkg/samplebuilder"
@@ -39,6 +44,8 @@ type LoadTester struct {
 	running                atomic.Bool
 	// participant ID => quality
 	trackQualities map[string]livekit.VideoQuality
+	// time taken by the successful join attempt
+	connectLatency atomic.Duration
 
 	stats *sync.Map
 }
@@ -63,15 +70,41 @@ const (
 	lowHeight    = 180
 )
 
-func LayoutFromString(str string) Layout {
-	if str == string(LayoutGrid3x3) {
-		return LayoutGrid3x3
-	} else if str == string(LayoutGrid4x4) {
-		return LayoutGrid4x4
-	} else if str == string(LayoutGrid5x5) {
-		return LayoutGrid5x5
+// maxGridSize caps either dimension of a custom NxM grid layout
+const maxGridSize = 20
+
+// LayoutFromString parses a named layout, or an arbitrary grid such as "6x6"
+// or "8x4" in which every tile is subscribed to.
+func LayoutFromString(str string) (Layout, error) {
+	switch Layout(str) {
+	case LayoutSpeaker, LayoutGrid3x3, LayoutGrid4x4, LayoutGrid5x5:
+		return Layout(str), nil
+	}
+	cols, rows, ok := strings.Cut(str, "x")
+	if !ok {
+		return "", fmt.Errorf("invalid layout %q, expected \"speaker\" or a grid such as \"3x3\"", str)
+	}
+	n, errN := strconv.Atoi(cols)
+	m, errM := strconv.Atoi(rows)
+	if errN != nil || errM != nil {
+		return "", fmt.Errorf("invalid grid layout %q, expected <columns>x<rows>, e.g. \"6x6\"", str)
 	}
-	return LayoutSpeaker
+	if n <= 0 || m <= 0 {
+		return "", fmt.Errorf("invalid grid layout %q, columns and rows must be positive", str)
+	}
+	if n > maxGridSize || m > maxGridSize {
+		return "", fmt.Errorf("grid layout %q is too large, at most %dx%d is supported", str, maxGridSize, maxGridSize)
+	}
+	return Layout(fmt.Sprintf("%dx%d", n, m)), nil
+}
+
+// tiles returns the number of tiles in a grid layout, or 0 for the speaker layout.
+func (l Layout) tiles() int {
+	var n, m int
+	if _, err := fmt.Sscanf(string(l), "%dx%d", &n, &m); err != nil {
+		return 0
+	}
+	return n * m
 }
 
 type TesterParams struct {
@@ -83,6 +116,10 @@ type TesterParams struct {
 	Layout         Layout
 	// true to subscribe to all published tracks
 	Subscribe bool
+	// true to only connect through TURN relays
+	ForceRelay bool
+	// how long each attempt to join the room may take, 0 for no limit
+	ConnectTimeout time.Duration
 
 	name           string
 	Sequence       int
@@ -113,18 +150,31 @@ func (t *LoadTester) Start() error {
 			OnTrackPublished: t.onTrackPublished,
 		},
 	})
+	opts := []lksdk.ConnectOption{lksdk.WithAutoSubscribe(false)}
+	if t.params.ForceRelay {
+		opts = append(opts, lksdk.WithICETransportPolicy(webrtc.ICETransportPolicyRelay))
+	}
 	var err error
 	// make up to 10 reconnect attempts
 	for i := 0; i < 10; i++ {
-		err = t.room.Join(t.params.URL, lksdk.ConnectInfo{
-			APIKey:              t.params.APIKey,
-			APISecret:           t.params.APISecret,
-			RoomName:            t.params.Room,
-			ParticipantIdentity: identity,
-		}, lksdk.WithAutoSubscribe(false))
+		attemptStart := time.Now()
+		_, err = util.CallWithTimeout(t.params.ConnectTimeout, func() (struct{}, error) {
+			return struct{}{}, t.room.Join(t.params.URL, lksdk.ConnectInfo{
+				APIKey:              t.params.APIKey,
+				APISecret:           t.params.APISecret,
+				RoomName:            t.params.Room,
+				ParticipantIdentity: identity,
+			}, opts...)
+		}, nil)
 		if err == nil {
+			t.connectLatency.Store(time.Since(attemptStart))
 			break
 		}
+		if errors.Is(err, util.ErrTimeout) {
+			// the attempt is still in flight on this room, so don't retry
+			t.room.Disconnect()
+			return fmt.Errorf("failed to connect within %s", t.params.ConnectTimeout)
+		}
 		time.Sleep(1 * time.Second)
 	}
 	if err != nil {
@@ -201,6 +251,44 @@ func (t *LoadTester) PublishVideoTrack(name, resolution, codec string) (string,
 	return p.SID(), nil
 }
 
+// PublishFileTrack publishes a VP8 .ivf or Opus .ogg file, looping it until
+// the tester is stopped.
+func (t *LoadTester) PublishFileTrack(name, path string) (string, error) {
+	if !t.IsRunning() {
+		return "", nil
+	}
+
+	fmt.Println("publishing file", path, "-", t.room.LocalParticipant.Identity())
+	var looper provider2.Looper
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ivf":
+		looper, err = provider2.CreateFileVideoLooper(path)
+	case ".ogg":
+		looper, err = provider2.CreateFileAudioLooper(path)
+	default:
+		err = fmt.Errorf("unsupported file %s, expected .ivf or .ogg", path)
+	}
+	if err != nil {
+		return "", err
+	}
+	track, err := lksdk.NewLocalTrack(looper.Codec())
+	if err != nil {
+		return "", err
+	}
+	if err := track.StartWrite(looper, nil); err != nil {
+		return "", err
+	}
+
+	p, err := t.room.LocalParticipant.PublishTrack(track, &lksdk.TrackPublicationOptions{
+		Name: name,
+	})
+	if err != nil {
+		return "", err
+	}
+	return p.SID(), nil
+}
+
 func (t *LoadTester) PublishSimulcastTrack(name, resolution, codec string) (string, error) {
 	var tracks []*lksdk.LocalTrack
 
@@ -281,6 +369,9 @@ func (t *LoadTester) numToSubscribe() int {
 	case LayoutGrid5x5:
 		return 25
 	default:
+		if tiles := t.params.Layout.tiles(); tiles > 0 {
+			return tiles
+		}
 		return 1
 	}
 }
@@ -357,6 +448,16 @@ func (t *LoadTester) onTrackSubscribed(track *webrtc.TrackRemote, pub *lksdk.Rem
 		if qualityCounts[livekit.VideoQuality_LOW] < 25 {
 			targetQuality = livekit.VideoQuality_LOW
 		}
+	default:
+		// custom grids use medium tiles up to 3x3 in size, low beyond that
+		tiles := t.params.Layout.tiles()
+		if tiles <= 9 {
+			if qualityCounts[livekit.VideoQuality_MEDIUM] < tiles {
+				targetQuality = livekit.VideoQuality_MEDIUM
+			}
+		} else if qualityCounts[livekit.VideoQuality_LOW] < tiles {
+			targetQuality = livekit.VideoQuality_LOW
+		}
 	}
 	t.trackQualities[rp.SID()] = targetQuality
 	t.lock.Unlock()
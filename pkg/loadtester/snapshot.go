@@ -0,0 +1,176 @@
+// Copyright 2024 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// statsSnapshot is a point-in-time view of every tester, written as one JSON
+// object per line so long runs can be graphed.
+type statsSnapshot struct {
+	Time    time.Time        `json:"time"`
+	Elapsed float64          `json:"elapsed_seconds"`
+	Testers []testerSnapshot `json:"testers"`
+	Total   testerSnapshot   `json:"total"`
+}
+
+type testerSnapshot struct {
+	Name             string  `json:"name"`
+	ConnectLatencyMs float64 `json:"connect_latency_ms"`
+	Tracks           int     `json:"tracks"`
+	ExpectedTracks   int     `json:"expected_tracks"`
+	Packets          int64   `json:"packets"`
+	Dropped          int64   `json:"dropped"`
+	PacketLoss       float64 `json:"packet_loss_percent"`
+	Bytes            int64   `json:"bytes"`
+	// bitrate over the interval since the previous snapshot
+	Bitrate float64 `json:"bitrate_bps"`
+	Error   string  `json:"error,omitempty"`
+}
+
+type statsWriter struct {
+	file    *os.File
+	enc     *json.Encoder
+	started time.Time
+
+	lock      sync.Mutex
+	testers   []*LoadTester
+	lastAt    time.Time
+	lastBytes map[string]int64
+	done      chan struct{}
+	stopped   chan struct{}
+}
+
+func newStatsWriter(path string) (*statsWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &statsWriter{
+		file:      f,
+		enc:       json.NewEncoder(f),
+		started:   now,
+		lastAt:    now,
+		lastBytes: make(map[string]int64),
+		done:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}, nil
+}
+
+func (w *statsWriter) add(tester *LoadTester) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.testers = append(w.testers, tester)
+}
+
+// start writes a snapshot every interval until close is called.
+func (w *statsWriter) start(interval time.Duration) {
+	go func() {
+		defer close(w.stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.done:
+				return
+			case <-ticker.C:
+				if err := w.write(nil); err != nil {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// close writes a final snapshot, including any tester errors, and closes the file.
+func (w *statsWriter) close(errs map[string]error) error {
+	close(w.done)
+	<-w.stopped
+	err := w.write(errs)
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (w *statsWriter) write(errs map[string]error) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	now := time.Now()
+	interval := now.Sub(w.lastAt).Seconds()
+	snapshot := statsSnapshot{
+		Time:    now,
+		Elapsed: now.Sub(w.started).Seconds(),
+		Testers: make([]testerSnapshot, 0, len(w.testers)),
+		Total:   testerSnapshot{Name: "Total"},
+	}
+	var latencySum time.Duration
+	var connected int
+	for _, tester := range w.testers {
+		stats := tester.getStats()
+		ts := testerSnapshot{
+			Name:           tester.params.name,
+			ExpectedTracks: stats.expectedTracks,
+		}
+		if latency := tester.connectLatency.Load(); latency > 0 {
+			ts.ConnectLatencyMs = float64(latency) / float64(time.Millisecond)
+			latencySum += latency
+			connected++
+		}
+		for _, track := range stats.trackStats {
+			ts.Tracks++
+			ts.Packets += track.packets.Load()
+			ts.Dropped += track.dropped.Load()
+			ts.Bytes += track.bytes.Load()
+		}
+		ts.PacketLoss = lossPercent(ts.Packets, ts.Dropped)
+		if interval > 0 {
+			ts.Bitrate = float64((ts.Bytes-w.lastBytes[ts.Name])*8) / interval
+		}
+		w.lastBytes[ts.Name] = ts.Bytes
+		if err := errs[ts.Name]; err != nil {
+			ts.Error = err.Error()
+		}
+
+		snapshot.Testers = append(snapshot.Testers, ts)
+		snapshot.Total.Tracks += ts.Tracks
+		snapshot.Total.ExpectedTracks += ts.ExpectedTracks
+		snapshot.Total.Packets += ts.Packets
+		snapshot.Total.Dropped += ts.Dropped
+		snapshot.Total.Bytes += ts.Bytes
+		snapshot.Total.Bitrate += ts.Bitrate
+	}
+	snapshot.Total.PacketLoss = lossPercent(snapshot.Total.Packets, snapshot.Total.Dropped)
+	if connected > 0 {
+		// average across connected testers
+		snapshot.Total.ConnectLatencyMs = float64(latencySum/time.Duration(connected)) / float64(time.Millisecond)
+	}
+	w.lastAt = now
+
+	return w.enc.Encode(snapshot)
+}
+
+func lossPercent(packets, dropped int64) float64 {
+	if packets+dropped == 0 {
+		return 0
+	}
+	return float64(dropped) / float64(packets+dropped) * 100
+}
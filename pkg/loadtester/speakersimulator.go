@@ -27,6 +27,8 @@ type SpeakerSimulatorParams struct {
 	Testers []*LoadTester
 	// amount of time between each speaker
 	Pause uint64
+	// number of testers that speak at the same time
+	ActiveSpeakers int
 }
 
 type SpeakerSimulator struct {
@@ -38,6 +40,12 @@ func NewSpeakerSimulator(params SpeakerSimulatorParams) *SpeakerSimulator {
 	if params.Pause == 0 {
 		params.Pause = 1
 	}
+	if params.ActiveSpeakers <= 0 {
+		params.ActiveSpeakers = 1
+	}
+	if params.ActiveSpeakers > len(params.Testers) {
+		params.ActiveSpeakers = len(params.Testers)
+	}
 	return &SpeakerSimulator{
 		params: params,
 	}
@@ -67,8 +75,9 @@ func (s *SpeakerSimulator) worker() {
 		case <-s.fuse.Watch():
 			return
 		case <-t.C:
-			speaker := s.params.Testers[rand.Intn(len(s.params.Testers))]
-			speaker.room.Simulate(lksdk.SimulateSpeakerUpdate)
+			for _, i := range rand.Perm(len(s.params.Testers))[:s.params.ActiveSpeakers] {
+				s.params.Testers[i].room.Simulate(lksdk.SimulateSpeakerUpdate)
+			}
 			t.Reset(time.Duration(s.params.Pause+lksdk.SimulateSpeakerUpdateInterval) * time.Second)
 		}
 	}
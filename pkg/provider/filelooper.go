@@ -0,0 +1,76 @@
+// Copyright 2024 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/pion/webrtc/v4/pkg/media/ivfreader"
+)
+
+const (
+	defaultFileFPS = 30
+	// frame rates outside this range come from timebases that aren't the
+	// frame rate, e.g. 1/90000 or 1/1000 as written by ffmpeg
+	minFileFPS = 1
+	maxFileFPS = 120
+)
+
+// CreateFileVideoLooper loops a VP8 .ivf file. The file is read into memory
+// once, its dimensions and frame rate are taken from the IVF header.
+func CreateFileVideoLooper(path string) (VideoLooper, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	_, header, err := ivfreader.NewWith(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if header.FourCC != "VP80" {
+		return nil, fmt.Errorf("%s: unsupported codec %q, only VP8 .ivf files can be looped", path, header.FourCC)
+	}
+	return NewVP8VideoLooper(bytes.NewReader(data), &videoSpec{
+		codec:  vp8Codec,
+		height: int(header.Height),
+		width:  int(header.Width),
+		fps:    ivfFPS(header),
+	})
+}
+
+// ivfFPS takes the frame rate from the IVF timebase when it's a plausible one,
+// falling back to defaultFileFPS otherwise.
+func ivfFPS(header *ivfreader.IVFFileHeader) int {
+	if header.TimebaseNumerator == 0 || header.TimebaseDenominator == 0 {
+		return defaultFileFPS
+	}
+	fps := int(header.TimebaseDenominator / header.TimebaseNumerator)
+	if fps < minFileFPS || fps > maxFileFPS {
+		return defaultFileFPS
+	}
+	return fps
+}
+
+// CreateFileAudioLooper loops an Opus .ogg file, read into memory once.
+func CreateFileAudioLooper(path string) (*OpusAudioLooper, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return NewOpusAudioLooper(f)
+}
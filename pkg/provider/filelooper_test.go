@@ -0,0 +1,60 @@
+// Copyright 2024 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/pion/webrtc/v4/pkg/media/ivfreader"
+)
+
+// ivfHeader returns the 32 byte header of a VP8 .ivf file with the given timebase.
+func ivfHeader(denominator, numerator uint32) []byte {
+	b := make([]byte, 32)
+	copy(b[0:], "DKIF")
+	binary.LittleEndian.PutUint16(b[6:], 32)
+	copy(b[8:], "VP80")
+	binary.LittleEndian.PutUint16(b[12:], 640)
+	binary.LittleEndian.PutUint16(b[14:], 360)
+	binary.LittleEndian.PutUint32(b[16:], denominator)
+	binary.LittleEndian.PutUint32(b[20:], numerator)
+	return b
+}
+
+func TestIVFFPS(t *testing.T) {
+	for _, tc := range []struct {
+		name                   string
+		denominator, numerator uint32
+		expected               int
+	}{
+		{"frame rate timebase", 30, 1, 30},
+		{"ntsc timebase", 30000, 1001, 29},
+		{"60 fps", 60, 1, 60},
+		{"ffmpeg mpeg timebase", 90000, 1, defaultFileFPS},
+		{"millisecond timebase", 1000, 1, defaultFileFPS},
+		{"zero numerator", 30, 0, defaultFileFPS},
+		{"below 1 fps", 1, 2, defaultFileFPS},
+	} {
+		_, header, err := ivfreader.NewWith(bytes.NewReader(ivfHeader(tc.denominator, tc.numerator)))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if fps := ivfFPS(header); fps != tc.expected {
+			t.Errorf("%s: expected %d fps, got %d", tc.name, tc.expected, fps)
+		}
+	}
+}
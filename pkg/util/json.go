@@ -16,10 +16,151 @@ package util
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"unicode"
 )
 
+const redactedValue = "****"
+
+// Words which, when found in a field name, mark its value as sensitive
+var sensitiveWords = []string{"secret", "password", "passwd", "key", "token", "credentials"}
+
 func PrintJSON(obj any) {
 	txt, _ := json.MarshalIndent(obj, "", "  ")
 	fmt.Println(string(txt))
 }
+
+// PrintJSONLine prints obj as compact JSON on a line of its own, so that a
+// stream of objects can be consumed as it is written, e.g. by jq.
+func PrintJSONLine(obj any) error {
+	return json.NewEncoder(os.Stdout).Encode(obj)
+}
+
+// ValidateJSON checks that s is well-formed JSON, pointing at the line and
+// column of the first syntax error when it isn't.
+func ValidateJSON(s string) error {
+	var v any
+	err := json.Unmarshal([]byte(s), &v)
+	if err == nil {
+		return nil
+	}
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		line, col := lineAndColumn(s, syntaxErr.Offset)
+		return fmt.Errorf("invalid JSON at line %d, column %d: %s", line, col, syntaxErr)
+	}
+	return fmt.Errorf("invalid JSON: %w", err)
+}
+
+func lineAndColumn(s string, offset int64) (int, int) {
+	line, col := 1, 1
+	for i, r := range s {
+		if int64(i) >= offset-1 {
+			break
+		}
+		if r == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return line, col
+}
+
+// Print obj as JSON, masking string values of any field whose name looks like
+// it holds a secret (keys, tokens, passwords, etc) and the stream keys of RTMP
+// and SRT URLs. The original obj is not modified.
+func PrintJSONRedacted(obj any) {
+	fmt.Println(string(RedactJSON(obj)))
+}
+
+// Marshal obj to indented JSON, masking sensitive string fields
+func RedactJSON(obj any) []byte {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return nil
+	}
+	var generic any
+	if err = json.Unmarshal(raw, &generic); err != nil {
+		return nil
+	}
+	txt, _ := json.MarshalIndent(redactValue(generic), "", "  ")
+	return txt
+}
+
+func redactValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, child := range val {
+			if s, ok := child.(string); ok && s != "" && IsSensitiveFieldName(k) {
+				val[k] = redactedValue
+			} else {
+				val[k] = redactValue(child)
+			}
+		}
+		return val
+	case []any:
+		for i, child := range val {
+			val[i] = redactValue(child)
+		}
+		return val
+	case string:
+		return redactStreamURL(val)
+	default:
+		return v
+	}
+}
+
+// RTMP and SRT URLs carry the stream key in their path or query, so those are
+// masked wherever such a URL appears, e.g. in stream_outputs.urls
+func redactStreamURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil {
+		return s
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "rtmp", "rtmps", "srt":
+	default:
+		return s
+	}
+	redacted := u.Scheme + "://" + u.Host
+	if u.Path != "" && u.Path != "/" {
+		redacted += "/" + redactedValue
+	}
+	if u.RawQuery != "" {
+		redacted += "?" + redactedValue
+	}
+	return redacted
+}
+
+// Reports whether a field name (snake_case, kebab-case or camelCase) contains
+// one of the sensitive words as a distinct word
+func IsSensitiveFieldName(name string) bool {
+	words := strings.FieldsFunc(splitCamelCase(name), func(r rune) bool {
+		return r == '_' || r == '-' || r == ' ' || r == '.'
+	})
+	for _, w := range words {
+		for _, s := range sensitiveWords {
+			if strings.EqualFold(w, s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func splitCamelCase(str string) string {
+	var b strings.Builder
+	for i, r := range str {
+		if i > 0 && unicode.IsUpper(r) {
+			b.WriteRune('_')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,96 @@
+// Copyright 2024 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsSensitiveFieldName(t *testing.T) {
+	for _, name := range []string{"secret", "access_key", "streamKey", "auth_password", "session-token", "credentials"} {
+		if !IsSensitiveFieldName(name) {
+			t.Errorf("%s should be considered sensitive", name)
+		}
+	}
+	for _, name := range []string{"room_name", "keyframes", "monkey", "tokenizer", "filepath"} {
+		if IsSensitiveFieldName(name) {
+			t.Errorf("%s should not be considered sensitive", name)
+		}
+	}
+}
+
+func TestRedactJSON(t *testing.T) {
+	type s3 struct {
+		AccessKey string  `json:"access_key"`
+		Secret    string  `json:"secret"`
+		Bucket    string  `json:"bucket"`
+		KeyFrame  float64 `json:"key_frame_interval"`
+	}
+	type request struct {
+		RoomName string `json:"room_name"`
+		Outputs  []s3   `json:"outputs"`
+	}
+	req := &request{
+		RoomName: "my-room",
+		Outputs:  []s3{{AccessKey: "AKIA123", Secret: "shh", Bucket: "my-bucket", KeyFrame: 2}},
+	}
+
+	out := string(RedactJSON(req))
+	if strings.Contains(out, "AKIA123") || strings.Contains(out, "shh") {
+		t.Error("redactJSON should mask sensitive values")
+	}
+	if !strings.Contains(out, "my-room") || !strings.Contains(out, "my-bucket") || !strings.Contains(out, "2") {
+		t.Error("redactJSON should preserve non-sensitive values")
+	}
+	if req.Outputs[0].Secret != "shh" {
+		t.Error("redactJSON should not mutate the original object")
+	}
+}
+
+func TestRedactJSONStreamURLs(t *testing.T) {
+	req := map[string]any{
+		"stream_outputs": []map[string]any{{
+			"urls": []string{
+				"rtmp://a.rtmp.youtube.com/live2/abcd-efgh-ijkl",
+				"srt://ingest.example.com:9000?streamid=publish/xyz123",
+			},
+		}},
+		"url": "https://example.com/live2/page",
+	}
+
+	out := string(RedactJSON(req))
+	if strings.Contains(out, "abcd-efgh-ijkl") || strings.Contains(out, "xyz123") {
+		t.Errorf("redactJSON should mask stream keys, got %s", out)
+	}
+	for _, want := range []string{"rtmp://a.rtmp.youtube.com/****", "srt://ingest.example.com:9000?****", "https://example.com/live2/page"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in %s", want, out)
+		}
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	if err := ValidateJSON(`{"role": "host"}`); err != nil {
+		t.Errorf("expected valid JSON, got %v", err)
+	}
+	err := ValidateJSON("{\n  \"role\": \"host\",\n}")
+	if err == nil {
+		t.Fatal("expected an error for a trailing comma")
+	}
+	if !strings.Contains(err.Error(), "line 3, column 1") {
+		t.Errorf("expected error to point at line 3, column 1, got %q", err)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2024 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Steps prints progress through a fixed sequence of named steps, e.g.
+// "[2/4] Instantiating environment...", and times each of them.
+type Steps struct {
+	out     io.Writer
+	total   int
+	current int
+	name    string
+	start   time.Time
+	begun   time.Time
+	timings []stepTiming
+}
+
+type stepTiming struct {
+	name     string
+	duration time.Duration
+}
+
+func NewSteps(out io.Writer, total int) *Steps {
+	return &Steps{
+		out:   out,
+		total: total,
+		start: time.Now(),
+	}
+}
+
+// Next finishes the current step, if any, and begins the next one.
+func (s *Steps) Next(name string) {
+	s.finish()
+	s.current++
+	s.name = name
+	s.begun = time.Now()
+	fmt.Fprintln(s.out, Theme.Focused.Title.Render(fmt.Sprintf("[%d/%d]", s.current, s.total)), name+"...")
+}
+
+// Current returns the number and name of the step in progress.
+func (s *Steps) Current() (int, string) {
+	return s.current, s.name
+}
+
+// Fail annotates err with the step in progress.
+func (s *Steps) Fail(err error) error {
+	if err == nil || s.current == 0 {
+		return err
+	}
+	return fmt.Errorf("step %d/%d (%s) failed: %w", s.current, s.total, s.name, err)
+}
+
+// Done finishes the last step and prints the total time taken along with the
+// time spent in each step.
+func (s *Steps) Done() {
+	s.finish()
+	parts := make([]string, 0, len(s.timings))
+	for _, t := range s.timings {
+		parts = append(parts, fmt.Sprintf("%s %s", strings.ToLower(t.name), t.duration.Round(time.Millisecond)))
+	}
+	fmt.Fprintf(s.out, "Done in %s (%s)\n", time.Since(s.start).Round(time.Millisecond), strings.Join(parts, ", "))
+}
+
+func (s *Steps) finish() {
+	if s.current == 0 || len(s.timings) == s.current {
+		return
+	}
+	s.timings = append(s.timings, stepTiming{name: s.name, duration: time.Since(s.begun)})
+}
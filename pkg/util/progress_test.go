@@ -0,0 +1,51 @@
+// Copyright 2024 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSteps(t *testing.T) {
+	var out bytes.Buffer
+	steps := NewSteps(&out, 2)
+
+	if err := steps.Fail(errors.New("early")); err.Error() != "early" {
+		t.Errorf("Fail before any step should not annotate, got %q", err)
+	}
+
+	steps.Next("Cloning template")
+	steps.Next("Installing")
+	if n, name := steps.Current(); n != 2 || name != "Installing" {
+		t.Errorf("unexpected current step %d %q", n, name)
+	}
+	if !strings.Contains(out.String(), "[2/2]") || !strings.Contains(out.String(), "Installing...") {
+		t.Errorf("missing step indicator in %q", out.String())
+	}
+
+	cause := errors.New("boom")
+	err := steps.Fail(cause)
+	if !errors.Is(err, cause) || !strings.Contains(err.Error(), "step 2/2 (Installing)") {
+		t.Errorf("unexpected failure %q", err)
+	}
+
+	steps.Done()
+	if !strings.Contains(out.String(), "cloning template") || !strings.Contains(out.String(), "installing") {
+		t.Errorf("summary should list each step, got %q", out.String())
+	}
+}
@@ -89,3 +89,37 @@ func URLSafeName(projectURL string) (string, error) {
 	}
 	return subdomain[:lastHyphen], nil
 }
+
+// ClosestMatch returns the option nearest to str by edit distance, provided it
+// is within maxDistance edits, or "" otherwise.
+func ClosestMatch(str string, options []string, maxDistance int) string {
+	best, bestDistance := "", maxDistance+1
+	for _, option := range options {
+		if d := editDistance(strings.ToLower(str), strings.ToLower(option)); d < bestDistance {
+			best, bestDistance = option, d
+		}
+	}
+	return best
+}
+
+// editDistance computes the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -63,3 +63,16 @@ func TestWrapToLines(t *testing.T) {
 		t.Error("wrapToLines should wrap the string to the specified width")
 	}
 }
+
+func TestClosestMatch(t *testing.T) {
+	options := []string{"grid", "speaker", "single-speaker"}
+	if m := ClosestMatch("speakr", options, 2); m != "speaker" {
+		t.Errorf("closestMatch should suggest speaker, got %q", m)
+	}
+	if m := ClosestMatch("Single_Speaker", options, 2); m != "single-speaker" {
+		t.Errorf("closestMatch should ignore case, got %q", m)
+	}
+	if m := ClosestMatch("carousel", options, 2); m != "" {
+		t.Errorf("closestMatch should not suggest distant options, got %q", m)
+	}
+}
@@ -15,11 +15,32 @@
 package util
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/table"
 )
 
-func CreateTable() *table.Table {
+var (
+	// TableColumns limits tables to the named columns, in the given order.
+	// Names are matched against the headers case-insensitively.
+	TableColumns []string
+	// TableNoHeaders omits the header row of tables
+	TableNoHeaders bool
+)
+
+// Table collects headers and rows so that TableColumns and TableNoHeaders can
+// be applied when it's rendered with Render. Commands build the full table
+// regardless.
+type Table struct {
+	*table.Table
+	headers   []string
+	rows      [][]string
+	styleFunc table.StyleFunc
+}
+
+func CreateTable() *Table {
 	styleFunc := func(row, col int) lipgloss.Style {
 		if row == table.HeaderRow {
 			return FormHeaderStyle
@@ -27,10 +48,110 @@ func CreateTable() *table.Table {
 		return FormBaseStyle
 	}
 
-	t := table.New().
-		Border(lipgloss.NormalBorder()).
-		BorderStyle(Theme.Form.Foreground(Fg)).
-		StyleFunc(styleFunc)
+	t := &Table{
+		Table: table.New().
+			Border(lipgloss.NormalBorder()).
+			BorderStyle(Theme.Form.Foreground(Fg)),
+	}
+	return t.StyleFunc(styleFunc)
+}
 
+func (t *Table) Headers(headers ...string) *Table {
+	t.headers = headers
+	t.Table.Headers(headers...)
 	return t
 }
+
+func (t *Table) Row(row ...string) *Table {
+	t.rows = append(t.rows, row)
+	t.Table.Row(row...)
+	return t
+}
+
+func (t *Table) Rows(rows ...[]string) *Table {
+	for _, row := range rows {
+		t.Row(row...)
+	}
+	return t
+}
+
+func (t *Table) StyleFunc(style table.StyleFunc) *Table {
+	t.styleFunc = style
+	t.Table.StyleFunc(style)
+	return t
+}
+
+func (t *Table) BorderRow(v bool) *Table {
+	t.Table.BorderRow(v)
+	return t
+}
+
+// Render renders the table limited to TableColumns, failing when one of them
+// isn't a column of this table.
+func (t *Table) Render() (string, error) {
+	if len(TableColumns) == 0 && !TableNoHeaders {
+		return t.Table.String(), nil
+	}
+
+	columns, err := t.selectColumns()
+	if err != nil {
+		return "", err
+	}
+	project := func(values []string) []string {
+		projected := make([]string, len(columns))
+		for i, c := range columns {
+			if c < len(values) {
+				projected[i] = values[c]
+			}
+		}
+		return projected
+	}
+
+	t.Table.ClearRows()
+	if TableNoHeaders {
+		t.Table.Headers()
+	} else {
+		t.Table.Headers(project(t.headers)...)
+	}
+	for _, row := range t.rows {
+		t.Table.Row(project(row)...)
+	}
+	t.Table.StyleFunc(func(row, col int) lipgloss.Style {
+		return t.styleFunc(row, columns[col])
+	})
+	return t.Table.String(), nil
+}
+
+// selectColumns returns the indexes of the columns to render.
+func (t *Table) selectColumns() ([]int, error) {
+	if len(TableColumns) == 0 {
+		columns := make([]int, max(len(t.headers), len(firstRow(t.rows))))
+		for i := range columns {
+			columns[i] = i
+		}
+		return columns, nil
+	}
+
+	columns := make([]int, 0, len(TableColumns))
+	for _, name := range TableColumns {
+		idx := -1
+		for i, h := range t.headers {
+			if strings.EqualFold(strings.TrimSpace(name), h) {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return nil, fmt.Errorf("unknown column %q, expected one of %s", name, strings.Join(t.headers, ", "))
+		}
+		columns = append(columns, idx)
+	}
+	return columns, nil
+}
+
+func firstRow(rows [][]string) []string {
+	if len(rows) == 0 {
+		return nil
+	}
+	return rows[0]
+}
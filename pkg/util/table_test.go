@@ -0,0 +1,44 @@
+// Copyright 2024 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTableSelectColumns(t *testing.T) {
+	defer func() { TableColumns = nil }()
+	table := CreateTable().Headers("RoomID", "Name", "Participants").Row("RM_1", "my-room", "2")
+
+	columns, err := table.selectColumns()
+	if err != nil || !slices.Equal(columns, []int{0, 1, 2}) {
+		t.Errorf("expected all columns, got %v, %v", columns, err)
+	}
+
+	TableColumns = []string{"participants", "roomid"}
+	columns, err = table.selectColumns()
+	if err != nil || !slices.Equal(columns, []int{2, 0}) {
+		t.Errorf("expected [2 0], got %v, %v", columns, err)
+	}
+
+	TableColumns = []string{"status"}
+	if _, err = table.selectColumns(); err == nil {
+		t.Error("expected an error for an unknown column")
+	}
+	if _, err = table.Render(); err == nil {
+		t.Error("expected Render to fail for an unknown column")
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2024 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"text/template"
+)
+
+// PrintTemplate renders each item with a Go text/template, one per line.
+// Fields are referenced by their Go names, e.g. '{{.EgressId}} {{.Status}}'.
+func PrintTemplate[T any](text string, items ...T) error {
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+	for _, item := range items {
+		if err = tmpl.Execute(os.Stdout, item); err != nil {
+			return err
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// FieldNames lists the exported fields of a struct type, for documenting
+// which fields are available to PrintTemplate.
+func FieldNames[T any]() []string {
+	typ := reflect.TypeFor[T]()
+	var names []string
+	for i := range typ.NumField() {
+		if f := typ.Field(i); f.IsExported() {
+			names = append(names, f.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,65 @@
+// Copyright 2024 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrTimeout = errors.New("timed out")
+
+// CallWithTimeout runs fn, giving up after timeout for calls that can't be
+// cancelled. Should fn succeed after the timeout, discard is called with its
+// result so that it can be cleaned up. A timeout of 0 waits indefinitely.
+func CallWithTimeout[T any](timeout time.Duration, fn func() (T, error), discard func(T)) (T, error) {
+	if timeout <= 0 {
+		return fn()
+	}
+
+	type result struct {
+		val T
+		err error
+	}
+	done := make(chan result)
+	abandoned := make(chan struct{})
+	go func() {
+		val, err := fn()
+		select {
+		case done <- result{val, err}:
+		case <-abandoned:
+			if err == nil && discard != nil {
+				discard(val)
+			}
+		}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.val, r.err
+	case <-timer.C:
+		close(abandoned)
+		// fn may have finished right as the timer fired
+		select {
+		case r := <-done:
+			return r.val, r.err
+		default:
+		}
+		var zero T
+		return zero, ErrTimeout
+	}
+}
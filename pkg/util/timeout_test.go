@@ -0,0 +1,49 @@
+// Copyright 2024 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCallWithTimeout(t *testing.T) {
+	val, err := CallWithTimeout(time.Second, func() (int, error) {
+		return 1, nil
+	}, nil)
+	if err != nil || val != 1 {
+		t.Errorf("expected 1, got %d, %v", val, err)
+	}
+
+	discarded := make(chan int, 1)
+	release := make(chan struct{})
+	_, err = CallWithTimeout(10*time.Millisecond, func() (int, error) {
+		<-release
+		return 2, nil
+	}, func(v int) { discarded <- v })
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("expected timeout, got %v", err)
+	}
+	close(release)
+	select {
+	case v := <-discarded:
+		if v != 2 {
+			t.Errorf("expected late result to be discarded, got %d", v)
+		}
+	case <-time.After(time.Second):
+		t.Error("late result was not discarded")
+	}
+}
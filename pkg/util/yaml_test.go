@@ -0,0 +1,42 @@
+// Copyright 2024 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import "testing"
+
+func TestMarshalYAML(t *testing.T) {
+	type room struct {
+		Name     string   `json:"name"`
+		Metadata string   `json:"metadata"`
+		Empty    string   `json:"empty"`
+		Count    int      `json:"count"`
+		Tags     []string `json:"tags"`
+	}
+	out, err := MarshalYAML(room{Name: "my-room", Metadata: "true", Count: 3, Tags: []string{"a", "123"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `name: my-room
+metadata: "true"
+empty: ""
+count: 3
+tags:
+    - a
+    - "123"
+`
+	if string(out) != expected {
+		t.Errorf("unexpected YAML:\n%s\nexpected:\n%s", out, expected)
+	}
+}